@@ -3,10 +3,10 @@ module github.com/LITFAMWOKE93/alleviated-wave
 go 1.21.5
 
 require (
+	github.com/aarzilli/nucular v0.0.0-20240117103348-47eb8d7bfc14
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240118000515-a250818d05e3
 	github.com/go-gl/mathgl v1.1.0
-
 )
 
 require (
@@ -15,7 +15,6 @@ require (
 	gioui.org/cpu v0.0.0-20210817075930-8d6a761490d2 // indirect
 	gioui.org/shader v1.0.6 // indirect
 	github.com/BurntSushi/xgb v0.0.0-20160522181843-27f122750802 // indirect
-	github.com/aarzilli/nucular v0.0.0-20240117103348-47eb8d7bfc14 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/go-text/typesetting v0.0.0-20230803102845-24e03d8b5372 // indirect
 	github.com/golang/freetype v0.0.0-20161208064710-d9be45aaf745 // indirect
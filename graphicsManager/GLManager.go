@@ -1,7 +1,9 @@
 package graphicsManager
 
 import (
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -11,26 +13,80 @@ import (
 )
 
 type GLManager struct {
-	Window   *glfw.Window
+	// Context is the window or headless context the manager renders with
+	Context Context
+	// Backend is where every graphics call goes, nil means real OpenGL
+	Backend  Backend
 	Program  uint32
 	vaos     []uint32
 	vbos     []uint32
 	vertices []mgl32.Vec4
 	// VertexColors    []mgl32.Vec4
-	vec4Storage     Vec4Storage
-	float32Storage  Float32Storage
-	float32vertices [][]float32
+	storage         VertexStorage
+	float32vertices []float32
 	FS              string
 	VS              string
 	RenderCall      func()
-}
 
+	// UpdateCall and RenderAlphaCall split the frame for RunLoop, fixed
+	// steps of dt and then a render alpha of the way into the next one.
+	// See loop.go.
+	UpdateCall      func(dt time.Duration)
+	RenderAlphaCall func(alpha float64)
+	Loop            LoopConfig
+	stopped         atomic.Bool
+
+	// Sources for the optional stages, see stages.go. CS on its own makes
+	// SetProgram link a compute program.
+	TCS string
+	TES string
+	GS  string
+	CS  string
+
+	// Preprocessor, when set, expands the sources before they are compiled,
+	// see preprocess.go
+	Preprocessor *Preprocessor
+	processed    map[string]*ProcessedSource
+
+	// watch is set by LoadShaders, see hotreload.go
+	watch *shaderWatch
+
+	// DebugHandler gets what EnableDebug reports, EnableDebug sets
+	// DefaultDebugHandler when it's nil. See debug.go.
+	DebugHandler func(DebugMessage)
+
+	// Index buffer state, see indices.go. indexType is gl.UNSIGNED_SHORT
+	// or gl.UNSIGNED_INT.
+	ebo              uint32
+	indices          []uint32
+	indexType        uint32
+	primitiveRestart bool
+
+	// Vertex layout state, see layout.go
+	layout       VertexLayout
+	vertexCount  int
+	layoutBound  bool
+	layoutReport LayoutReport
+
+	// pool streams per frame data, see pool.go
+	pool *BufferPool
+
+	// shader caches uniform locations for Program, see program.go
+	shader *ShaderProgram
+
+	// provider is set when NewGLManager created the context and owns it
+	provider ContextProvider
+}
+
+// VerticeStorer is anything that keeps named streams of vertex data,
+// VertexStorage is the implementation GLManager uploads from
 type VerticeStorer interface {
 	GetAll() ([][]mgl32.Vec4, error)
 	PutSlice([]mgl32.Vec4, string) error
-	PutVal(mgl32.Vec4)
+	PutVal(mgl32.Vec4) error
 }
 
+// Vec4Storage is the position and color view of the storage the demos read
 type Vec4Storage struct {
 	ObjectVertices []mgl32.Vec4
 	VertexColors   []mgl32.Vec4
@@ -46,7 +102,16 @@ func (glm *GLManager) NewVec4Storage() Vec4Storage {
 }
 
 func (glm *GLManager) Vec4Storage() Vec4Storage {
-	return glm.vec4Storage
+	return Vec4Storage{
+		ObjectVertices: glm.storage.Stream(StreamPosition),
+		VertexColors:   glm.storage.Stream(StreamColor),
+	}
+}
+
+// Storage is the manager's vertex storage, streams added here are what
+// BindVBOs and BindLayout upload
+func (glm *GLManager) Storage() *VertexStorage {
+	return &glm.storage
 }
 
 func (v4s *Vec4Storage) ClearAll() {
@@ -63,8 +128,13 @@ type Float32Storage struct {
 	VertexColorFloats []float32
 }
 
+// Float32Storage is the position and color streams flattened for the
+// buffers, the floats share memory with the storage
 func (glm *GLManager) Float32Storage() Float32Storage {
-	return glm.float32Storage
+	return Float32Storage{
+		ObjVecFloats:      Vec4sAsFloat32(glm.storage.Stream(StreamPosition)),
+		VertexColorFloats: Vec4sAsFloat32(glm.storage.Stream(StreamColor)),
+	}
 }
 
 func (glm *GLManager) NewFloat32Storage() Float32Storage {
@@ -76,101 +146,94 @@ func (glm *GLManager) NewFloat32Storage() Float32Storage {
 	return result
 }
 
-func (glm *GLManager) GetAll() (slice [][]mgl32.Vec4, err error) {
-	for _, val := range slice {
-		fmt.Printf("All slices in storage: %v", val)
-	}
-
-	return slice, nil
-}
-
-func (glm *GLManager) PutSlice(slice []mgl32.Vec4, selection string) (err error) {
-	switch selection {
-	case "object":
-		fmt.Println("Object storage appending")
-		fmt.Printf("Value: %v", slice)
-		glm.vec4Storage.ObjectVertices = append(glm.vec4Storage.ObjectVertices, slice...)
-	case "color":
-		fmt.Println("Color storage appending")
-		fmt.Printf("Value: %v", slice)
-		glm.vec4Storage.VertexColors = append(glm.vec4Storage.VertexColors, slice...)
-	default:
-		fmt.Println("Must input object or color as selection in string format")
-		return fmt.Errorf(err.Error())
-	}
-
-	return nil
+// GetAll, PutSlice and PutVal forward to the manager's VertexStorage so
+// GLManager can be handed around as a VerticeStorer itself
+func (glm *GLManager) GetAll() ([][]mgl32.Vec4, error) {
+	return glm.storage.GetAll()
 }
 
-func NewWindowContext(width, height int, windowTitle string) *glfw.Window {
-
-	if err := glfw.Init(); err != nil {
-		fmt.Println("glfw.Init() failed:", err)
-		return nil
-	}
-	defer glfw.Terminate()
-
-	// Information needed for the window
+func (glm *GLManager) PutSlice(slice []mgl32.Vec4, selection string) error {
+	return glm.storage.PutSlice(slice, selection)
+}
 
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+func (glm *GLManager) PutVal(value mgl32.Vec4) error {
+	return glm.storage.PutVal(value)
+}
 
-	// Creating of the window context
+// NewWindowContext creates a 4.1 core window and makes its context current.
+// glfw stays initialized, the caller owns the window and calls glfw.Terminate.
+func NewWindowContext(width, height int, windowTitle string) (*glfw.Window, error) {
+	provider := &GLFWProvider{}
 
-	window, err := glfw.CreateWindow(width, height, windowTitle, nil, nil)
+	ctx, err := provider.NewContext(DefaultContextConfig(width, height, windowTitle))
 	if err != nil {
-		fmt.Println("glfw.CreateWindow() failed:", err)
-		return nil
+		return nil, &ContextError{Op: "create window", Err: err}
 	}
 
-	// Binding of the window context
-	window.MakeContextCurrent()
-
-	return window
-
+	return ctx.(*GLFWContext).Window(), nil
 }
 
-func (glm *GLManager) NewProgram() uint32 {
-
-	fmt.Println("OpenGL Version:", gl.GoStr(gl.GetString(gl.VERSION)))
+// NewProgram compiles and links VS and FS. A failure comes back as a
+// *ShaderCompileError or *ProgramLinkError carrying the driver's log.
+func (glm *GLManager) NewProgram() (uint32, error) {
 
-	program, err := newProgram(glm.VertexShaderSource(), glm.FragmentShaderSource())
+	stages, err := glm.programStages()
 	if err != nil {
-		fmt.Println("Shader program creation failed:", err)
-		return 0
+		return 0, err
+	}
+	processed := map[string]*ProcessedSource{}
+	if pre := glm.preprocessor(); pre != nil {
+		for i, stage := range stages {
+			name := shaderStageName(stage.xtype)
+			p, err := pre.Process(glm.stageFile(name), stage.source)
+			if err != nil {
+				return 0, err
+			}
+			processed[name] = p
+			stages[i].source = p.Code
+		}
 	}
+	glm.processed = processed
 
-	return program
+	program, err := newProgram(glm.backend(), stages...)
+	var compileErr *ShaderCompileError
+	if errors.As(err, &compileErr) {
+		compileErr.Processed = processed[compileErr.Stage]
+	}
 
+	return program, err
 }
 
-//func NewGLManager(width, height int, windowTitle string) GLManager {
-
-//result := GLManager{
-//	window: NewWindowContext(width, height, windowTitle),
-//TODO: Make setters and getters for uninitilized values
-//}
-
-///	return result
-//}
-
-func (glm *GLManager) BindProgram() {
-	if glm.GetProgram() != 0 {
-		gl.UseProgram(glm.GetProgram())
-		fmt.Println("BindProgram called")
-	} else {
-		fmt.Println("Program value is 0")
+func (glm *GLManager) BindProgram() error {
+	if glm.GetProgram() == 0 {
+		return ErrNoProgram
 	}
+	glm.backend().UseProgram(glm.GetProgram())
+
+	return nil
 }
 
 func (glm *GLManager) GetProgram() uint32 {
 	return glm.Program
 }
 
+// GetWindow is the glfw window behind the context, nil for headless contexts
 func (glm *GLManager) GetWindow() *glfw.Window {
-	return glm.Window
+	if ctx, ok := glm.Context.(*GLFWContext); ok {
+		return ctx.Window()
+	}
+
+	return nil
+}
+
+// backend falls back to the real OpenGL calls so a GLManager literal
+// without a Backend keeps behaving the way the demos expect
+func (glm *GLManager) backend() Backend {
+	if glm.Backend == nil {
+		glm.Backend = GLBackend{}
+	}
+
+	return glm.Backend
 }
 
 // Any function with Get in front of it is redundant habbit
@@ -189,7 +252,7 @@ func (glm *GLManager) Vertices() []mgl32.Vec4 {
 	return glm.vertices
 }
 
-func (glm *GLManager) Float32Vertices() [][]float32 {
+func (glm *GLManager) Float32Vertices() []float32 {
 	return glm.float32vertices
 }
 
@@ -201,35 +264,33 @@ func (glm *GLManager) VertexShaderSource() string {
 	return glm.VS
 }
 
-func (glm *GLManager) SetShaderSource(shaderSource, shaderType string) {
-
-	switch shaderType {
-	case "vertex":
-		glm.VS = shaderSource
-	case "fragment":
-		glm.FS = shaderSource
-	default:
-		fmt.Println("Unsupported shader type, please declare \"vertex\" or \"fragment\"")
+func (glm *GLManager) SetShaderSource(shaderSource, shaderType string) error {
+	source := glm.stageSource(shaderType)
+	if source == nil {
+		return fmt.Errorf("%w: %q, please declare one of %s", ErrUnsupportedShaderType, shaderType, stageNames())
 	}
+	*source = shaderSource
 
+	return nil
 }
 
+// SetGeoVertices replaces the position stream, the slice is also kept as
+// the working vertices ConvertVec3ToFloat32 reads
 func (glm *GLManager) SetGeoVertices(sliceVec4 []mgl32.Vec4) {
-	glm.vec4Storage.ObjectVertices = sliceVec4
-	glm.float32Storage.ObjVecFloats = vec4ToFloat32(sliceVec4)
+	glm.storage.Set(StreamPosition, sliceVec4)
+	glm.vertices = sliceVec4
 }
 
 func (glm *GLManager) SetColorVertices(sliceVec4 []mgl32.Vec4) {
-	glm.vec4Storage.VertexColors = sliceVec4
-	glm.float32Storage.VertexColorFloats = vec4ToFloat32(sliceVec4)
+	glm.storage.Set(StreamColor, sliceVec4)
 }
 
 func (glm *GLManager) GetGeoVertices() []float32 {
-	return glm.float32Storage.ObjVecFloats
+	return Vec4sAsFloat32(glm.storage.Stream(StreamPosition))
 }
 
 func (glm *GLManager) GetColorVertices() []float32 {
-	return glm.float32Storage.VertexColorFloats
+	return Vec4sAsFloat32(glm.storage.Stream(StreamColor))
 }
 
 func (glm *GLManager) ClearVertices() {
@@ -244,156 +305,206 @@ func (glm *GLManager) SetFloat32Vertices() {
 
 }
 
-func (glm *GLManager) SetProgram() {
-	glm.Program = glm.NewProgram()
+// SetProgram builds a new program from VS and FS and keeps it on success,
+// the previous program is left in place when compiling or linking fails
+// and deleted when it is replaced
+func (glm *GLManager) SetProgram() error {
+	program, err := glm.NewProgram()
+	if err != nil {
+		return err
+	}
+	shader := glm.shader
+	glm.ReleaseProgram()
+	glm.Program = program
+	// Reflect right away so ActiveUniforms is ready without a draw. The
+	// ShaderProgram is kept across relinks so a demo holding on to
+	// glm.Shader() sees the new locations after a reload.
+	if shader == nil {
+		shader = &ShaderProgram{backend: glm.backend}
+	}
+	shader.ID = program
+	shader.Refresh()
+	glm.shader = shader
+
+	return nil
+}
+
+// ReleaseProgram deletes the linked program, safe to call when there is none
+func (glm *GLManager) ReleaseProgram() {
+	if glm.Program != 0 {
+		glm.backend().DeleteProgram(glm.Program)
+		glm.Program = 0
+		glm.shader = nil
+	}
 }
 
 func (glm *GLManager) ClearFloat32Vertices() {
 	glm.float32vertices = nil
 }
 
+// BindVAOs makes one VAO per VBO, VBOs that already have one are skipped
+// so calling it again after a rebuild doesn't pile up vertex arrays
 func (glm *GLManager) BindVAOs() {
 	// Multiple Vaos
-	for _, vbo := range glm.VBOs() {
-		newVao := makeVao(vbo)
+	for _, vbo := range glm.VBOs()[min(len(glm.vaos), len(glm.vbos)):] {
+		newVao := makeVao(glm.backend(), vbo)
 		glm.vaos = append(glm.vaos, newVao)
 	}
 }
 
 // Multiple Vbos
+// The first call creates the geometry and color buffers, later calls
+// upload the current storage into those same two buffers
 func (glm *GLManager) BindVBOs() {
 
-	newVbo := makeVbo(glm.float32Storage.ObjVecFloats)
+	floats := glm.Float32Storage()
+	glm.layoutBound = false
+	glm.storage.MarkClean()
+	if len(glm.vbos) == 2 {
+		uploadVbo(glm.backend(), glm.vbos[0], floats.ObjVecFloats)
+		uploadVbo(glm.backend(), glm.vbos[1], floats.VertexColorFloats)
+		return
+	}
+	glm.ReleaseVBOs()
+
+	newVbo := makeVbo(glm.backend(), floats.ObjVecFloats)
 	glm.vbos = append(glm.vbos, newVbo)
-	newVbo = makeVbo(glm.float32Storage.VertexColorFloats)
+	newVbo = makeVbo(glm.backend(), floats.VertexColorFloats)
 	glm.vbos = append(glm.vbos, newVbo)
 
 }
 
-func (glm *GLManager) ConvertVec3ToFloat32() [][]float32 {
-	var tempSlice [][]float32
+// ReleaseVBOs deletes every buffer BindVBOs made
+func (glm *GLManager) ReleaseVBOs() {
+	for _, vbo := range glm.vbos {
+		glm.backend().DeleteBuffer(vbo)
+	}
+	glm.vbos = nil
+}
+
+// ReleaseVAOs deletes every vertex array BindVAOs made
+func (glm *GLManager) ReleaseVAOs() {
+	for _, vao := range glm.vaos {
+		glm.backend().DeleteVertexArray(vao)
+	}
+	glm.vaos = nil
+}
+
+// Destroy frees everything the manager made on the GPU, the vertex arrays,
+// buffers, element buffer, buffer pool and program. The context must still
+// be current, Terminate calls it before tearing the context down. Calling
+// it twice does nothing. With a LeakTracker backend anything still alive
+// afterwards is printed.
+func (glm *GLManager) Destroy() {
+	glm.ReleaseVAOs()
+	glm.ReleaseVBOs()
+	glm.ReleaseEBO()
+	if glm.pool != nil {
+		glm.pool.Release()
+	}
+	glm.ReleaseProgram()
+	glm.reportLeaks()
+}
 
-	newSlice := vec4ToFloat32(glm.vertices)
-	tempSlice = append(tempSlice, newSlice)
+// ConvertVec3ToFloat32 flattens the working vertices as vec3, w is dropped
+func (glm *GLManager) ConvertVec3ToFloat32() []float32 {
+	float32Array := make([]float32, 0, len(glm.vertices)*3)
+	for _, vec := range glm.vertices {
+		float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z())
+	}
 
-	return tempSlice
+	return float32Array
 }
 
+// Render draws one frame outside of RunLoop, RenderAlphaCall gets alpha 1,
+// the latest update as it is
 func (glm *GLManager) Render() {
-	if glm.RenderCall != nil {
-		glm.RenderCall()
-	} else {
-		fmt.Println("Render call function is nil")
-	}
+	glm.render(1)
 }
 
-func newProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
+// newProgram compiles and links whichever stages it is given, every
+// shader compiled so far is deleted again when a later one fails
+func newProgram(b Backend, stages ...stageSource) (uint32, error) {
 
 	// Compile the shaders from the given source, turning it into a uint32 value
-
-	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
-	if err != nil {
-		fmt.Println("VertexShaderError")
-		return 0, err
-
+	var shaders []uint32
+	for _, stage := range stages {
+		shader, err := compileShader(b, stage.source, stage.xtype)
+		if err != nil {
+			for _, s := range shaders {
+				b.DeleteShader(s)
+			}
+			return 0, err
+		}
+		shaders = append(shaders, shader)
 	}
 
-	fragmentShader, err := compileShader(fragmentShaderSource, gl.FRAGMENT_SHADER)
-	if err != nil {
-		fmt.Println("FragmentShaderError")
-		return 0, err
+	// Create program, attach shaders, and link them
+	program := b.CreateProgram()
+	for _, shader := range shaders {
+		b.AttachShader(program, shader)
 	}
+	b.LinkProgram(program)
 
-	// Create program, attach shaders, and link them
-	program := gl.CreateProgram()
-	gl.AttachShader(program, vertexShader)
-	gl.AttachShader(program, fragmentShader)
-	gl.LinkProgram(program)
+	// The program keeps the linked code, the shader objects are only flagged
+	// here and GL frees them once the program is deleted
+	for _, shader := range shaders {
+		b.DeleteShader(shader)
+	}
 
-	var status int32
 	// Check program attributes for errors
-	gl.GetProgramiv(program, gl.LINK_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetProgramiv(program, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := make([]byte, logLength)
-		gl.GetProgramInfoLog(program, logLength, nil, &log[0])
-
-		return 0, fmt.Errorf("program link error: %s", string(log))
+	if b.GetProgramiv(program, gl.LINK_STATUS) == gl.FALSE {
+		log := b.GetProgramInfoLog(program)
+		b.DeleteProgram(program)
+		return 0, &ProgramLinkError{InfoLog: log}
 	}
 
 	return program, nil
 }
 
-func compileShader(source string, shaderType uint32) (uint32, error) {
+func compileShader(b Backend, source string, shaderType uint32) (uint32, error) {
 	// Create a shader uint32 from the c sources provided
-	shader := gl.CreateShader(shaderType)
-	csources, free := gl.Strs(source)
-	gl.ShaderSource(shader, 1, csources, nil)
-	free()
-	gl.CompileShader(shader)
-
-	var status int32
-	gl.GetShaderiv(shader, gl.COMPILE_STATUS, &status)
-	if status == gl.FALSE {
-		var logLength int32
-		gl.GetShaderiv(shader, gl.INFO_LOG_LENGTH, &logLength)
-
-		log := make([]byte, logLength)
-		gl.GetShaderInfoLog(shader, logLength, nil, &log[0])
-
-		return 0, fmt.Errorf("shader compile error: %s", string(log))
+	shader := b.CreateShader(shaderType)
+	b.ShaderSource(shader, source)
+	b.CompileShader(shader)
+
+	if b.GetShaderiv(shader, gl.COMPILE_STATUS) == gl.FALSE {
+		log := b.GetShaderInfoLog(shader)
+		b.DeleteShader(shader)
+		return 0, &ShaderCompileError{Stage: shaderStageName(shaderType), Source: source, InfoLog: log}
 	}
 
 	return shader, nil
 }
 
-func makeVao(vbo uint32) uint32 {
+func makeVao(b Backend, vbo uint32) uint32 {
 	fmt.Println("makeVao called")
 	// Vertex array is generated and bound, attributes are set up so we know how many to read at a time and what data type will be read
 
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
+	vao := b.GenVertexArray()
 
 	return vao
 }
 
-func makeVbo(vertices []float32) uint32 {
+func makeVbo(b Backend, vertices []float32) uint32 {
 	fmt.Println("Make vbo called")
 	// The first binding of the buffer when called at initialization
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-
-	return vbo
-}
-
-func vec4ToFloat32(vec4Array []mgl32.Vec4) []float32 {
-	// This is my version of "flatten.js" as I am working with mgl32.Vec3 structs in Go to do vector math but need them squashed into an array of float32 to feed the buffer
-	float32Array := make([]float32, 0, len(vec4Array)*4)
-
-	for _, vec := range vec4Array {
-		float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z(), vec.W())
+	vbo := b.GenBuffer()
+	if len(vertices) > 0 {
+		uploadVbo(b, vbo, vertices)
 	}
 
-	return float32Array
+	return vbo
 }
 
-// RunLoop is where the rendering and buffering take place
-func (glm *GLManager) RunLoop(fps int) {
-	t := time.Now()
-	for !glm.GetWindow().ShouldClose() {
-
-		//Render call
-		glm.Render()
-
-		//Check for errors after each call
-
-		glfw.PollEvents()
-		glm.GetWindow().SwapBuffers()
-
-		time.Sleep(time.Second/time.Duration(fps) - time.Since(t))
-		t = time.Now()
-
+// uploadVbo replaces the contents of an existing buffer
+func uploadVbo(b Backend, vbo uint32, vertices []float32) {
+	b.BindBuffer(gl.ARRAY_BUFFER, vbo)
+	// 32 bits 4 bytes
+	if len(vertices) > 0 {
+		b.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
+	} else {
+		b.BufferData(gl.ARRAY_BUFFER, 0, nil, gl.STATIC_DRAW)
 	}
+	b.BindBuffer(gl.ARRAY_BUFFER, 0)
 }
@@ -0,0 +1,123 @@
+package graphicsManager
+
+import "unsafe"
+
+// Backend is the set of graphics calls GLManager is allowed to make.
+// GLBackend forwards every call to OpenGL 4.1, RecordingBackend writes
+// them down so the manager can be exercised on machines without a GPU.
+// The method names and argument orders follow the gl package so the
+// two read the same, names are plain Go strings and the backend takes
+// care of any null termination.
+type Backend interface {
+	// Buffers
+	GenBuffer() uint32
+	BindBuffer(target, buffer uint32)
+	BufferData(target uint32, size int, data unsafe.Pointer, usage uint32)
+	BufferSubData(target uint32, offset, size int, data unsafe.Pointer)
+	DeleteBuffer(buffer uint32)
+
+	// Vertex arrays
+	GenVertexArray() uint32
+	BindVertexArray(vao uint32)
+	DeleteVertexArray(vao uint32)
+	EnableVertexAttribArray(index uint32)
+	VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uintptr)
+	VertexAttribDivisor(index, divisor uint32)
+
+	// Shaders
+	CreateShader(shaderType uint32) uint32
+	ShaderSource(shader uint32, source string)
+	CompileShader(shader uint32)
+	GetShaderiv(shader, pname uint32) int32
+	GetShaderInfoLog(shader uint32) string
+	DeleteShader(shader uint32)
+
+	// Programs
+	CreateProgram() uint32
+	AttachShader(program, shader uint32)
+	LinkProgram(program uint32)
+	GetProgramiv(program, pname uint32) int32
+	GetProgramInfoLog(program uint32) string
+	UseProgram(program uint32)
+	DeleteProgram(program uint32)
+	GetAttribLocation(program uint32, name string) int32
+	// index runs up to GetProgramiv(ACTIVE_UNIFORMS/ACTIVE_ATTRIBUTES)
+	GetActiveUniform(program, index uint32) (name string, size int32, xtype uint32)
+	GetActiveAttrib(program, index uint32) (name string, size int32, xtype uint32)
+
+	// Tessellation and compute, DispatchCompute and MemoryBarrier need a
+	// 4.3 context and fail with ErrNoGL43 without one
+	PatchParameteri(pname uint32, value int32)
+	DispatchCompute(x, y, z uint32) error
+	MemoryBarrier(barriers uint32) error
+	BindBufferBase(target, index, buffer uint32)
+
+	// Uniforms, the slice variants upload len(v)/components elements
+	GetUniformLocation(program uint32, name string) int32
+	Uniform1i(location int32, v int32)
+	Uniform1f(location int32, v float32)
+	Uniform1iv(location int32, v []int32)
+	Uniform1fv(location int32, v []float32)
+	Uniform2fv(location int32, v []float32)
+	Uniform3fv(location int32, v []float32)
+	Uniform4fv(location int32, v []float32)
+	UniformMatrix3fv(location int32, transpose bool, v []float32)
+	UniformMatrix4fv(location int32, transpose bool, v []float32)
+
+	// Textures
+	GenTexture() uint32
+	BindTexture(target, texture uint32)
+	TexImage2D(target uint32, level, internalFormat, width, height int32, format, xtype uint32, pixels unsafe.Pointer)
+	TexParameteri(target, pname uint32, param int32)
+	DeleteTexture(texture uint32)
+
+	// Framebuffers and renderbuffers
+	GenFramebuffer() uint32
+	BindFramebuffer(target, framebuffer uint32)
+	FramebufferTexture2D(target, attachment, texTarget, texture uint32, level int32)
+	FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer uint32)
+	CheckFramebufferStatus(target uint32) uint32
+	DeleteFramebuffer(framebuffer uint32)
+	GenRenderbuffer() uint32
+	BindRenderbuffer(target, renderbuffer uint32)
+	RenderbufferStorage(target, internalFormat uint32, width, height int32)
+	DeleteRenderbuffer(renderbuffer uint32)
+	ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer)
+
+	// Sync objects, a sync is the GLsync pointer as an integer like in the gl package
+	FenceSync(condition, flags uint32) uintptr
+	ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32
+	DeleteSync(sync uintptr)
+
+	// State and drawing
+	Enable(capability uint32)
+	Disable(capability uint32)
+	ClearColor(r, g, b, a float32)
+	Clear(mask uint32)
+	Viewport(x, y, width, height int32)
+	// GetViewport is the current viewport, GL_VIEWPORT is four values so it
+	// can't go through GetIntegerv
+	GetViewport() (x, y, width, height int32)
+	DrawArrays(mode uint32, first, count int32)
+	// DrawElements reads count indices of xtype from the element array
+	// buffer bound to the current VAO, starting offset bytes in
+	DrawElements(mode uint32, count int32, xtype uint32, offset uintptr)
+	PrimitiveRestartIndex(index uint32)
+	GetError() uint32
+	GetString(name uint32) string
+	GetStringi(name, index uint32) string
+	GetIntegerv(pname uint32) int32
+
+	// Debug output, 4.3 contexts only. DebugMessageCallback installs
+	// callback, nil removes it. Both fail with ErrNoGL43 when the driver
+	// has no entry point for them.
+	DebugMessageCallback(callback func(DebugMessage)) error
+	DebugMessageControl(source, xtype, severity uint32, enabled bool) error
+}
+
+// fixedBackend is the backend getter for objects made outside a manager,
+// the ones a manager makes read glm.backend so they see EnableDebug's
+// ErrorChecker
+func fixedBackend(b Backend) func() Backend {
+	return func() Backend { return b }
+}
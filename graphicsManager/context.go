@@ -0,0 +1,58 @@
+package graphicsManager
+
+// Context is an OpenGL context together with whatever it presents to. A
+// glfw window is one, an EGL pbuffer or surfaceless context is another.
+type Context interface {
+	MakeCurrent() error
+	SwapBuffers()
+	PollEvents()
+	ShouldClose() bool
+	SetShouldClose(value bool)
+	// FramebufferSize is the size of the default framebuffer in pixels
+	FramebufferSize() (width, height int)
+	// SetSwapInterval is 1 for vsync and 0 to swap as fast as possible
+	SetSwapInterval(interval int)
+	Destroy()
+}
+
+// ContextProvider creates contexts from one windowing or platform API.
+// gl.Init still has to be called once the returned context is current.
+type ContextProvider interface {
+	NewContext(config ContextConfig) (Context, error)
+	// Terminate releases the provider, any context it made is invalid after
+	Terminate()
+}
+
+// ContextConfig describes the context and surface a provider should create
+type ContextConfig struct {
+	Width, Height int
+	Title         string
+
+	VersionMajor, VersionMinor int
+	CoreProfile                bool
+	ForwardCompatible          bool
+
+	// Samples is the MSAA sample count, 0 turns multisampling off
+	Samples   int
+	Resizable bool
+	// Hidden keeps a window provider from showing its window
+	Hidden bool
+	// Debug asks for a debug context, drivers report more through
+	// KHR_debug in one
+	Debug bool
+}
+
+// DefaultContextConfig is the 4.1 core forward compatible context every
+// demo asks for
+func DefaultContextConfig(width, height int, title string) ContextConfig {
+	return ContextConfig{
+		Width:             width,
+		Height:            height,
+		Title:             title,
+		VersionMajor:      4,
+		VersionMinor:      1,
+		CoreProfile:       true,
+		ForwardCompatible: true,
+		Resizable:         true,
+	}
+}
@@ -0,0 +1,229 @@
+//go:build linux && egl
+
+package graphicsManager
+
+/*
+#cgo pkg-config: egl
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+// The surfaceless platform lets Mesa run without any display server, older
+// loaders without the extension fall back to the default display.
+static EGLDisplay headlessDisplay() {
+	PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay =
+		(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+	if (getPlatformDisplay != NULL) {
+		EGLDisplay display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+		if (display != EGL_NO_DISPLAY) {
+			return display;
+		}
+	}
+	return eglGetDisplay(EGL_DEFAULT_DISPLAY);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// EGL 1.5 context attributes, spelled out so older headers still build
+const (
+	eglContextMajorVersion        = 0x3098
+	eglContextMinorVersion        = 0x30FB
+	eglContextOpenGLProfileMask   = 0x30FD
+	eglContextOpenGLCoreBit       = 0x00000001
+	eglContextOpenGLForwardCompat = 0x31B1
+	eglContextOpenGLDebug         = 0x31B0
+)
+
+// EGLProvider creates headless contexts through EGL, rendering into a
+// pbuffer when the driver offers one and surfaceless otherwise. With Mesa's
+// llvmpipe this runs the real GL code path in a container with no display.
+// Build with -tags egl so go-gl/gl also loads its functions through EGL.
+type EGLProvider struct {
+	display C.EGLDisplay
+	once    sync.Once
+	err     error
+}
+
+func NewEGLProvider() *EGLProvider {
+	return &EGLProvider{}
+}
+
+func (p *EGLProvider) init() error {
+	p.once.Do(func() {
+		p.display = C.headlessDisplay()
+		if p.display == C.EGLDisplay(C.EGL_NO_DISPLAY) {
+			p.err = fmt.Errorf("eglGetDisplay found no display")
+			return
+		}
+		var major, minor C.EGLint
+		if C.eglInitialize(p.display, &major, &minor) == C.EGL_FALSE {
+			p.err = fmt.Errorf("eglInitialize failed: 0x%X", C.eglGetError())
+		}
+	})
+
+	return p.err
+}
+
+func (p *EGLProvider) chooseConfig(config ContextConfig, surfaceType C.EGLint) (C.EGLConfig, bool) {
+	attribs := []C.EGLint{
+		C.EGL_SURFACE_TYPE, surfaceType,
+		C.EGL_RENDERABLE_TYPE, C.EGL_OPENGL_BIT,
+		C.EGL_RED_SIZE, 8,
+		C.EGL_GREEN_SIZE, 8,
+		C.EGL_BLUE_SIZE, 8,
+		C.EGL_ALPHA_SIZE, 8,
+		C.EGL_DEPTH_SIZE, 24,
+		C.EGL_SAMPLES, C.EGLint(config.Samples),
+		C.EGL_NONE,
+	}
+
+	var eglConfig C.EGLConfig
+	var count C.EGLint
+	if C.eglChooseConfig(p.display, &attribs[0], &eglConfig, 1, &count) == C.EGL_FALSE || count == 0 {
+		return eglConfig, false
+	}
+
+	return eglConfig, true
+}
+
+func (p *EGLProvider) NewContext(config ContextConfig) (Context, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	if C.eglBindAPI(C.EGL_OPENGL_API) == C.EGL_FALSE {
+		return nil, fmt.Errorf("eglBindAPI(EGL_OPENGL_API) failed: 0x%X", C.eglGetError())
+	}
+
+	surfaceless := false
+	eglConfig, ok := p.chooseConfig(config, C.EGL_PBUFFER_BIT)
+	if !ok {
+		if eglConfig, ok = p.chooseConfig(config, 0); !ok {
+			return nil, fmt.Errorf("eglChooseConfig found no matching config: 0x%X", C.eglGetError())
+		}
+		surfaceless = true
+	}
+
+	contextAttribs := []C.EGLint{
+		eglContextMajorVersion, C.EGLint(config.VersionMajor),
+		eglContextMinorVersion, C.EGLint(config.VersionMinor),
+	}
+	if config.CoreProfile {
+		contextAttribs = append(contextAttribs, eglContextOpenGLProfileMask, eglContextOpenGLCoreBit)
+	}
+	if config.ForwardCompatible {
+		contextAttribs = append(contextAttribs, eglContextOpenGLForwardCompat, C.EGL_TRUE)
+	}
+	if config.Debug {
+		contextAttribs = append(contextAttribs, eglContextOpenGLDebug, C.EGL_TRUE)
+	}
+	contextAttribs = append(contextAttribs, C.EGL_NONE)
+
+	eglContext := C.eglCreateContext(p.display, eglConfig, C.EGLContext(C.EGL_NO_CONTEXT), &contextAttribs[0])
+	if eglContext == C.EGLContext(C.EGL_NO_CONTEXT) {
+		return nil, fmt.Errorf("eglCreateContext failed: 0x%X", C.eglGetError())
+	}
+
+	ctx := &EGLContext{
+		display: p.display,
+		context: eglContext,
+		surface: C.EGLSurface(C.EGL_NO_SURFACE),
+		width:   config.Width,
+		height:  config.Height,
+	}
+
+	if !surfaceless {
+		surfaceAttribs := []C.EGLint{
+			C.EGL_WIDTH, C.EGLint(config.Width),
+			C.EGL_HEIGHT, C.EGLint(config.Height),
+			C.EGL_NONE,
+		}
+		ctx.surface = C.eglCreatePbufferSurface(p.display, eglConfig, &surfaceAttribs[0])
+		if ctx.surface == C.EGLSurface(C.EGL_NO_SURFACE) {
+			ctx.Destroy()
+			return nil, fmt.Errorf("eglCreatePbufferSurface failed: 0x%X", C.eglGetError())
+		}
+	}
+
+	if err := ctx.MakeCurrent(); err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// Terminate releases the display, a second call does nothing and the next
+// NewContext initializes EGL again
+func (p *EGLProvider) Terminate() {
+	if p.display != C.EGLDisplay(C.EGL_NO_DISPLAY) {
+		C.eglTerminate(p.display)
+	}
+	p.display = C.EGLDisplay(C.EGL_NO_DISPLAY)
+	p.once = sync.Once{}
+	p.err = nil
+}
+
+// EGLContext is a headless Context. Without a surface the default
+// framebuffer doesn't exist, render into a Framebuffer instead.
+type EGLContext struct {
+	display     C.EGLDisplay
+	context     C.EGLContext
+	surface     C.EGLSurface
+	width       int
+	height      int
+	shouldClose bool
+}
+
+// Surfaceless reports whether the context has no pbuffer behind it
+func (c *EGLContext) Surfaceless() bool {
+	return c.surface == C.EGLSurface(C.EGL_NO_SURFACE)
+}
+
+func (c *EGLContext) MakeCurrent() error {
+	if C.eglMakeCurrent(c.display, c.surface, c.surface, c.context) == C.EGL_FALSE {
+		return fmt.Errorf("eglMakeCurrent failed: 0x%X", C.eglGetError())
+	}
+
+	return nil
+}
+
+func (c *EGLContext) SwapBuffers() {
+	if !c.Surfaceless() {
+		C.eglSwapBuffers(c.display, c.surface)
+	}
+}
+
+// PollEvents has nothing to poll, there is no window
+func (c *EGLContext) PollEvents() {}
+
+func (c *EGLContext) ShouldClose() bool {
+	return c.shouldClose
+}
+
+func (c *EGLContext) SetShouldClose(value bool) {
+	c.shouldClose = value
+}
+
+func (c *EGLContext) FramebufferSize() (int, int) {
+	return c.width, c.height
+}
+
+func (c *EGLContext) SetSwapInterval(interval int) {
+	C.eglSwapInterval(c.display, C.EGLint(interval))
+}
+
+func (c *EGLContext) Destroy() {
+	C.eglMakeCurrent(c.display, C.EGLSurface(C.EGL_NO_SURFACE), C.EGLSurface(C.EGL_NO_SURFACE), C.EGLContext(C.EGL_NO_CONTEXT))
+	if !c.Surfaceless() {
+		C.eglDestroySurface(c.display, c.surface)
+		c.surface = C.EGLSurface(C.EGL_NO_SURFACE)
+	}
+	if c.context != C.EGLContext(C.EGL_NO_CONTEXT) {
+		C.eglDestroyContext(c.display, c.context)
+		c.context = C.EGLContext(C.EGL_NO_CONTEXT)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !(linux && egl)
+
+package graphicsManager
+
+import "errors"
+
+var errEGLUnavailable = errors.New("headless EGL contexts need a linux build with -tags egl")
+
+// EGLProvider is only functional in linux builds tagged egl, elsewhere it
+// reports that so callers can fall back to a window or the software backend
+type EGLProvider struct{}
+
+func NewEGLProvider() *EGLProvider {
+	return &EGLProvider{}
+}
+
+func (p *EGLProvider) NewContext(config ContextConfig) (Context, error) {
+	return nil, errEGLUnavailable
+}
+
+func (p *EGLProvider) Terminate() {}
@@ -0,0 +1,60 @@
+//go:build linux && egl
+
+package graphicsManager
+
+import (
+	"image/color"
+	"runtime"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+// Runs the real GL path headlessly, e.g. on Mesa's llvmpipe:
+// LIBGL_ALWAYS_SOFTWARE=1 go test -tags egl ./graphicsManager
+func TestEGLProvider_RenderToImage(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	provider := NewEGLProvider()
+	defer provider.Terminate()
+
+	ctx, err := provider.NewContext(DefaultContextConfig(16, 16, "headless"))
+	if err != nil {
+		t.Skip("no EGL driver available:", err)
+	}
+	defer ctx.Destroy()
+	assert.NoError(t, gl.Init())
+
+	manager := GLManager{Context: ctx}
+	manager.RenderCall = func() {
+		manager.backend().ClearColor(0, 1, 0, 1)
+		manager.backend().Clear(gl.COLOR_BUFFER_BIT)
+	}
+
+	img, err := manager.RenderToImage(16, 16)
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, img.RGBAAt(8, 8))
+}
+
+func TestEGLProvider_TerminateTwice(t *testing.T) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	provider := NewEGLProvider()
+	ctx, err := provider.NewContext(DefaultContextConfig(16, 16, "headless"))
+	if err != nil {
+		t.Skip("no EGL driver available:", err)
+	}
+	ctx.Destroy()
+	provider.Terminate()
+	provider.Terminate()
+
+	// Terminated providers start over instead of reusing the dead display
+	ctx, err = provider.NewContext(DefaultContextConfig(16, 16, "headless"))
+	if assert.NoError(t, err) {
+		ctx.Destroy()
+	}
+	provider.Terminate()
+}
@@ -0,0 +1,117 @@
+package graphicsManager
+
+import (
+	"fmt"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// GLFWProvider creates windowed contexts through glfw. glfw has to be used
+// from the main thread, so callers lock it with runtime.LockOSThread first.
+type GLFWProvider struct {
+	initialized bool
+}
+
+func (p *GLFWProvider) NewContext(config ContextConfig) (Context, error) {
+	// A failed first window shouldn't leave glfw running behind it
+	initialized := false
+	if !p.initialized {
+		if err := glfw.Init(); err != nil {
+			return nil, fmt.Errorf("glfw.Init() failed: %w", err)
+		}
+		p.initialized = true
+		initialized = true
+	}
+
+	glfw.DefaultWindowHints()
+	glfw.WindowHint(glfw.ContextVersionMajor, config.VersionMajor)
+	glfw.WindowHint(glfw.ContextVersionMinor, config.VersionMinor)
+	if config.CoreProfile {
+		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	}
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfwBool(config.ForwardCompatible))
+	glfw.WindowHint(glfw.Samples, config.Samples)
+	glfw.WindowHint(glfw.Resizable, glfwBool(config.Resizable))
+	glfw.WindowHint(glfw.Visible, glfwBool(!config.Hidden))
+	glfw.WindowHint(glfw.OpenGLDebugContext, glfwBool(config.Debug))
+
+	window, err := glfw.CreateWindow(config.Width, config.Height, config.Title, nil, nil)
+	if err != nil {
+		if initialized {
+			p.Terminate()
+		}
+		return nil, fmt.Errorf("glfw.CreateWindow() failed: %w", err)
+	}
+
+	ctx := WrapGLFWWindow(window)
+	if err := ctx.MakeCurrent(); err != nil {
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+func (p *GLFWProvider) Terminate() {
+	if p.initialized {
+		glfw.Terminate()
+		p.initialized = false
+	}
+}
+
+// GLFWContext is a Context backed by a glfw window
+type GLFWContext struct {
+	window *glfw.Window
+}
+
+// WrapGLFWWindow turns a window created elsewhere into a Context
+func WrapGLFWWindow(window *glfw.Window) *GLFWContext {
+	return &GLFWContext{window: window}
+}
+
+// Window gives access to the glfw window for input callbacks
+func (c *GLFWContext) Window() *glfw.Window {
+	return c.window
+}
+
+func (c *GLFWContext) MakeCurrent() error {
+	c.window.MakeContextCurrent()
+	return nil
+}
+
+func (c *GLFWContext) SwapBuffers() {
+	c.window.SwapBuffers()
+}
+
+func (c *GLFWContext) PollEvents() {
+	glfw.PollEvents()
+}
+
+func (c *GLFWContext) ShouldClose() bool {
+	return c.window.ShouldClose()
+}
+
+func (c *GLFWContext) SetShouldClose(value bool) {
+	c.window.SetShouldClose(value)
+}
+
+func (c *GLFWContext) FramebufferSize() (int, int) {
+	return c.window.GetFramebufferSize()
+}
+
+// SetSwapInterval applies to the current context, so this one is made current first
+func (c *GLFWContext) SetSwapInterval(interval int) {
+	c.window.MakeContextCurrent()
+	glfw.SwapInterval(interval)
+}
+
+func (c *GLFWContext) Destroy() {
+	c.window.Destroy()
+}
+
+func glfwBool(value bool) int {
+	if value {
+		return glfw.True
+	}
+
+	return glfw.False
+}
@@ -0,0 +1,186 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// DebugSeverity is a KHR_debug severity, the values are the GL enums
+type DebugSeverity uint32
+
+const (
+	DebugSeverityNotification DebugSeverity = 0x826B
+	DebugSeverityLow          DebugSeverity = 0x9148
+	DebugSeverityMedium       DebugSeverity = 0x9147
+	DebugSeverityHigh         DebugSeverity = 0x9146
+)
+
+// rank orders the severities, the enum values aren't in order
+func (s DebugSeverity) rank() int {
+	switch s {
+	case DebugSeverityHigh:
+		return 3
+	case DebugSeverityMedium:
+		return 2
+	case DebugSeverityLow:
+		return 1
+	}
+
+	return 0
+}
+
+// AtLeast reports whether s is min or worse
+func (s DebugSeverity) AtLeast(min DebugSeverity) bool {
+	return s.rank() >= min.rank()
+}
+
+func (s DebugSeverity) String() string {
+	return strings.ToLower(strings.TrimPrefix(GLEnumName(uint32(s)), "GL_DEBUG_SEVERITY_"))
+}
+
+// DebugMessage is one message from the driver's debug output, or an error
+// code the ErrorChecker got back from GetError
+type DebugMessage struct {
+	Source uint32
+	Type   uint32
+	// ID is the error code for GetError errors, driver specific otherwise
+	ID       uint32
+	Severity DebugSeverity
+	Message  string
+	// Call is the Backend method that failed, ErrorChecker only
+	Call string
+	// File and Line are the code that made the GL call, when it's known
+	File string
+	Line int
+}
+
+// String reads like "GL high api error: GL_INVALID_ENUM in BindBuffer at main.go:12"
+func (m DebugMessage) String() string {
+	source := strings.ToLower(strings.TrimPrefix(GLEnumName(m.Source), "GL_DEBUG_SOURCE_"))
+	xtype := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(GLEnumName(m.Type), "GL_DEBUG_TYPE_")), "_", " ")
+	s := fmt.Sprintf("GL %s %s %s: %s", m.Severity, strings.ReplaceAll(source, "_", " "), xtype, m.Message)
+	if m.File != "" {
+		s += fmt.Sprintf(" at %s:%d", m.File, m.Line)
+	}
+
+	return s
+}
+
+// glPackage is this package's import path plus the dot, to tell its own
+// frames apart from the caller's
+var glPackage = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}()
+
+// glCaller finds the code that made a GL call: the first frame that isn't
+// the runtime, cgo, the gl bindings or this package, tests in the package
+// count as callers. When everything is internal the innermost frame
+// outside the debug layer is used.
+func glCaller(skip int) (string, int) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+1, pcs)])
+	var first *runtime.Frame
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, glPackage) && !strings.HasSuffix(frame.File, "_test.go")
+		switch {
+		case strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "github.com/go-gl/") ||
+			strings.HasPrefix(frame.Function, "_cgo") || frame.Function == "":
+			// The KHR_debug callback comes back into Go through cgo
+		case strings.HasPrefix(frame.Function, glPackage+"(*ErrorChecker)") || strings.HasPrefix(frame.Function, glPackage+"(*GLManager).EnableDebug"):
+		case internal:
+			if first == nil {
+				first = &frame
+			}
+		default:
+			return frame.File, frame.Line
+		}
+		if !more {
+			break
+		}
+	}
+	if first != nil {
+		return first.File, first.Line
+	}
+
+	return "", 0
+}
+
+// hasKHRDebug reports whether the context has the debug callback. Only 4.3
+// contexts count, a 4.1 driver listing GL_KHR_debug doesn't help since the
+// callback is loaded with the rest of the 4.3 bindings and those won't load
+// there.
+func (glm *GLManager) hasKHRDebug() bool {
+	major, minor := glm.GLVersion()
+	return major > 4 || major == 4 && minor >= 3
+}
+
+// DefaultDebugHandler is the DebugHandler EnableDebug installs when there
+// isn't one, it writes each message to stderr
+func DefaultDebugHandler(m DebugMessage) {
+	fmt.Fprintln(os.Stderr, m)
+}
+
+// EnableDebug turns on GL error reporting. On a 4.3 context the driver's
+// KHR_debug callback is installed, synchronous so every message comes with
+// the file and line of the call behind it, and messages below min are
+// filtered out. Otherwise the backend is wrapped in an ErrorChecker, which
+// only sees errors and only from calls made through the manager.
+//
+// Messages go to DebugHandler, DefaultDebugHandler is set when it's nil.
+// It returns whether the callback is what's being used, and when the
+// callback was there but failed to install, why. The ErrorChecker is on
+// either way.
+func (glm *GLManager) EnableDebug(min DebugSeverity) (bool, error) {
+	if glm.DebugHandler == nil {
+		glm.DebugHandler = DefaultDebugHandler
+	}
+	b := glm.backend()
+	var err error
+	if glm.hasKHRDebug() {
+		err = b.DebugMessageCallback(func(m DebugMessage) {
+			if !m.Severity.AtLeast(min) {
+				return
+			}
+			m.File, m.Line = glCaller(1)
+			glm.debugMessage(m)
+		})
+		if err == nil {
+			b.Enable(DebugOutput)
+			b.Enable(DebugOutputSynchronous)
+			// Everything off, then back on from min up
+			err = b.DebugMessageControl(gl.DONT_CARE, gl.DONT_CARE, gl.DONT_CARE, false)
+			for _, s := range []DebugSeverity{DebugSeverityNotification, DebugSeverityLow, DebugSeverityMedium, DebugSeverityHigh} {
+				if err == nil && s.AtLeast(min) {
+					err = b.DebugMessageControl(gl.DONT_CARE, gl.DONT_CARE, uint32(s), true)
+				}
+			}
+			if err == nil {
+				return true, nil
+			}
+			// Unfiltered output would be all notifications, GetError it is
+			b.DebugMessageCallback(nil)
+			b.Disable(DebugOutput)
+		}
+	}
+
+	if _, ok := b.(*ErrorChecker); !ok {
+		glm.Backend = NewErrorChecker(b, glm.debugMessage)
+	}
+
+	return false, err
+}
+
+func (glm *GLManager) debugMessage(m DebugMessage) {
+	if glm.DebugHandler != nil {
+		glm.DebugHandler(m)
+	}
+}
@@ -0,0 +1,187 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGLEnumName(t *testing.T) {
+	assert.Equal(t, "GL_INVALID_ENUM", GLEnumName(gl.INVALID_ENUM))
+	assert.Equal(t, "GL_FRAMEBUFFER_INCOMPLETE_ATTACHMENT", GLEnumName(gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT))
+	assert.Equal(t, "GL_DEBUG_SEVERITY_HIGH", GLEnumName(uint32(DebugSeverityHigh)))
+	assert.Equal(t, "0x1234", GLEnumName(0x1234))
+	// 0 only means NO_ERROR when it came from GetError
+	assert.Equal(t, "0x0000", GLEnumName(0))
+	assert.Equal(t, "GL_NO_ERROR", GLErrorName(gl.NO_ERROR))
+
+	assert.Equal(t, "framebuffer incomplete: GL_FRAMEBUFFER_UNSUPPORTED", (&FramebufferError{Status: gl.FRAMEBUFFER_UNSUPPORTED}).Error())
+}
+
+func TestDebugSeverity(t *testing.T) {
+	assert.True(t, DebugSeverityHigh.AtLeast(DebugSeverityMedium))
+	assert.True(t, DebugSeverityMedium.AtLeast(DebugSeverityMedium))
+	assert.False(t, DebugSeverityLow.AtLeast(DebugSeverityMedium))
+	assert.True(t, DebugSeverityNotification.AtLeast(DebugSeverityNotification))
+	assert.Equal(t, "medium", DebugSeverityMedium.String())
+
+	m := DebugMessage{Source: DebugSourceShaderCompiler, Type: DebugTypePerformance, Severity: DebugSeverityLow, Message: "recompiled", File: "cube.go", Line: 9}
+	assert.Equal(t, "GL low shader compiler performance: recompiled at cube.go:9", m.String())
+}
+
+// here is the file and line of the statement after it
+func here() (string, int) {
+	_, file, line, _ := runtime.Caller(1)
+	return file, line + 1
+}
+
+func TestErrorChecker(t *testing.T) {
+	rec := &RecordingBackend{}
+	var got []DebugMessage
+	checker := NewErrorChecker(rec, func(m DebugMessage) { got = append(got, m) })
+
+	checker.BindBuffer(gl.ARRAY_BUFFER, 1)
+	assert.Empty(t, got)
+
+	rec.PushError(gl.INVALID_ENUM)
+	rec.PushError(gl.INVALID_VALUE)
+	file, line := here()
+	checker.Enable(0xBAD)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "Enable", got[0].Call)
+		assert.Equal(t, uint32(gl.INVALID_ENUM), got[0].ID)
+		assert.Equal(t, DebugSeverityHigh, got[0].Severity)
+		assert.Equal(t, "GL_INVALID_ENUM in Enable", got[0].Message)
+		assert.Equal(t, file, got[0].File)
+		assert.Equal(t, line, got[0].Line)
+		assert.Equal(t, "GL_INVALID_VALUE in Enable", got[1].Message)
+	}
+
+	// Errors are still there for anyone calling GetError themselves
+	rec.PushError(gl.OUT_OF_MEMORY)
+	assert.Equal(t, uint32(gl.OUT_OF_MEMORY), checker.GetError())
+}
+
+func TestGLManager_EnableDebugChecksErrors(t *testing.T) {
+	rec := &RecordingBackend{}
+	var got []DebugMessage
+	manager := GLManager{Backend: NewLeakTracker(rec), DebugHandler: func(m DebugMessage) { got = append(got, m) }}
+
+	callback, err := manager.EnableDebug(DebugSeverityMedium)
+	assert.False(t, callback)
+	assert.NoError(t, err)
+	assert.IsType(t, &ErrorChecker{}, manager.Backend)
+	callback, _ = manager.EnableDebug(DebugSeverityMedium)
+	assert.False(t, callback)
+	assert.Equal(t, rec, manager.Backend.(*ErrorChecker).Backend.(*LeakTracker).Backend, "wrapped once")
+
+	// The report points past the manager to the code that called it
+	rec.PushError(gl.INVALID_OPERATION)
+	file, line := here()
+	assert.ErrorIs(t, manager.SetPatchVertices(0), ErrInvalidConfig)
+	manager.SetPatchVertices(4)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "PatchParameteri", got[0].Call)
+		assert.Equal(t, filepath.Base(file), filepath.Base(got[0].File))
+		assert.Equal(t, line+1, got[0].Line)
+	}
+	assert.Zero(t, rec.Count("DebugMessageCallback"))
+}
+
+func TestGLManager_EnableDebugCallback(t *testing.T) {
+	rec := &RecordingBackend{Version: "4.6 (Core Profile) Mesa"}
+	var got []DebugMessage
+	manager := GLManager{Backend: rec, DebugHandler: func(m DebugMessage) { got = append(got, m) }}
+
+	callback, err := manager.EnableDebug(DebugSeverityMedium)
+	assert.True(t, callback)
+	assert.NoError(t, err)
+	assert.Same(t, rec, manager.Backend)
+	assert.True(t, rec.Enabled(DebugOutput))
+	assert.True(t, rec.Enabled(DebugOutputSynchronous))
+	controls := rec.CallsNamed("DebugMessageControl")
+	if assert.Len(t, controls, 3) {
+		assert.Equal(t, []interface{}{uint32(gl.DONT_CARE), uint32(gl.DONT_CARE), uint32(gl.DONT_CARE), false}, controls[0].Args)
+		assert.Equal(t, uint32(DebugSeverityMedium), controls[1].Args[2])
+		assert.Equal(t, uint32(DebugSeverityHigh), controls[2].Args[2])
+	}
+
+	rec.EmitDebugMessage(DebugMessage{Source: DebugSourceAPI, Type: DebugTypePerformance, Severity: DebugSeverityLow, Message: "slow"})
+	file, line := here()
+	rec.EmitDebugMessage(DebugMessage{Source: DebugSourceAPI, Type: DebugTypeError, ID: gl.INVALID_ENUM, Severity: DebugSeverityHigh, Message: "bad enum"})
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, "bad enum", got[0].Message)
+		assert.Equal(t, file, got[0].File)
+		assert.Equal(t, line, got[0].Line)
+	}
+
+	// The extension on a 4.1 context can't be loaded, that's GetError
+	assert.False(t, (&GLManager{Backend: &RecordingBackend{Extensions: []string{"GL_KHR_debug"}}}).hasKHRDebug())
+}
+
+func TestGLManager_EnableDebugDefaultHandler(t *testing.T) {
+	manager := GLManager{Backend: &RecordingBackend{}}
+	manager.EnableDebug(DebugSeverityHigh)
+	assert.NotNil(t, manager.DebugHandler)
+
+	// A handler set afterwards gets the messages instead
+	var got []DebugMessage
+	manager.DebugHandler = func(m DebugMessage) { got = append(got, m) }
+	manager.Backend.(*ErrorChecker).Backend.(*RecordingBackend).PushError(gl.INVALID_VALUE)
+	manager.SetPatchVertices(3)
+	assert.Len(t, got, 1)
+}
+
+// noDebugControl has the callback but not the filter, like a driver with
+// a half loaded KHR_debug
+type noDebugControl struct {
+	*RecordingBackend
+}
+
+func (noDebugControl) DebugMessageControl(source, xtype, severity uint32, enabled bool) error {
+	return fmt.Errorf("%w: DebugMessageControl: missing", ErrNoGL43)
+}
+
+func TestGLManager_EnableDebugControlFails(t *testing.T) {
+	rec := &RecordingBackend{Version: "4.6 (Core Profile) Mesa"}
+	manager := GLManager{Backend: noDebugControl{rec}}
+
+	// The callback is taken out again and GetError is checked instead
+	callback, err := manager.EnableDebug(DebugSeverityMedium)
+	assert.False(t, callback)
+	assert.ErrorIs(t, err, ErrNoGL43)
+	assert.IsType(t, &ErrorChecker{}, manager.Backend)
+	if calls := rec.CallsNamed("DebugMessageCallback"); assert.Len(t, calls, 2) {
+		assert.Equal(t, []any{false}, calls[1].Args)
+	}
+	assert.False(t, rec.Enabled(DebugOutput))
+}
+
+func TestGLManager_EnableDebugReachesExistingObjects(t *testing.T) {
+	rec := &RecordingBackend{}
+	var got []DebugMessage
+	manager := GLManager{Backend: rec, Program: 1, DebugHandler: func(m DebugMessage) { got = append(got, m) }}
+
+	// All made before the ErrorChecker goes in
+	shader := manager.Shader()
+	ring := manager.BufferPool().NewRing(1, 0)
+	fb, err := manager.NewFramebuffer(4, 4)
+	assert.NoError(t, err)
+	manager.EnableDebug(DebugSeverityHigh)
+
+	rec.PushError(gl.INVALID_OPERATION)
+	assert.NoError(t, shader.Use())
+	rec.PushError(gl.INVALID_OPERATION)
+	ring.Release()
+	rec.PushError(gl.INVALID_OPERATION)
+	fb.Bind()
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "UseProgram", got[0].Call)
+		assert.Equal(t, "DeleteBuffer", got[1].Call)
+		assert.Equal(t, "GetViewport", got[2].Call)
+	}
+}
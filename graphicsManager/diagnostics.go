@@ -0,0 +1,206 @@
+package graphicsManager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity of one compiler message
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+
+	return "error"
+}
+
+// Diagnostic is one message out of a compiler info log
+type Diagnostic struct {
+	Stage string
+	// File is where the line is, the stage name when the source wasn't
+	// preprocessed and there is only the one string
+	File string
+	// Line and Column are 1 based, 0 when the driver didn't say
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+	// Text is the source line the message points at, when it's known
+	Text string
+}
+
+// The info log formats differ per vendor
+var (
+	// Mesa: 0:17(5): error: `oops' undeclared
+	mesaDiagnostic = regexp.MustCompile(`^\s*\d+:(\d+)\((\d+)\):\s*(?:preprocessor\s+)?(error|warning|info)\s*:\s*(.*)$`)
+	// NVIDIA: 0(17) : error C1008: undefined variable "oops"
+	nvidiaDiagnostic = regexp.MustCompile(`^\s*\d+\((\d+)\)\s*:\s*(fatal error|error|warning|info)\s*(.*)$`)
+	// AMD, Intel on Windows, Apple and ANGLE: ERROR: 0:17: 'oops' : undeclared identifier
+	amdDiagnostic = regexp.MustCompile(`^\s*(ERROR|WARNING|INFO):\s*\d+:(\d+):\s*(.*)$`)
+	// AMD closes with a count that isn't a diagnostic of its own
+	amdSummary = regexp.MustCompile(`^\s*(ERROR|WARNING):\s*\d+ compilation (errors|warnings)`)
+)
+
+// ParseInfoLog splits a compiler info log into diagnostics. Lines that
+// match no known format are taken as the continuation of the previous
+// message, or as a message without a line when they come first.
+func ParseInfoLog(log string) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimRight(line, "\r\x00")
+		if strings.TrimSpace(line) == "" || amdSummary.MatchString(line) {
+			continue
+		}
+
+		if d, ok := parseDiagnostic(line); ok {
+			diagnostics = append(diagnostics, d)
+		} else if len(diagnostics) > 0 {
+			last := &diagnostics[len(diagnostics)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+		} else {
+			diagnostics = append(diagnostics, Diagnostic{Message: strings.TrimSpace(line)})
+		}
+	}
+
+	return diagnostics
+}
+
+func parseDiagnostic(line string) (Diagnostic, bool) {
+	if m := mesaDiagnostic.FindStringSubmatch(line); m != nil {
+		l, _ := strconv.Atoi(m[1])
+		c, _ := strconv.Atoi(m[2])
+		return Diagnostic{Line: l, Column: c, Severity: parseSeverity(m[3]), Message: m[4]}, true
+	}
+	if m := nvidiaDiagnostic.FindStringSubmatch(line); m != nil {
+		l, _ := strconv.Atoi(m[1])
+		return Diagnostic{Line: l, Severity: parseSeverity(m[2]), Message: m[3]}, true
+	}
+	if m := amdDiagnostic.FindStringSubmatch(line); m != nil {
+		l, _ := strconv.Atoi(m[2])
+		return Diagnostic{Line: l, Severity: parseSeverity(m[1]), Message: m[3]}, true
+	}
+
+	return Diagnostic{}, false
+}
+
+func parseSeverity(s string) Severity {
+	switch strings.ToLower(s) {
+	case "warning":
+		return SeverityWarning
+	case "info":
+		return SeverityInfo
+	}
+
+	return SeverityError
+}
+
+// Diagnostics parses InfoLog and points every message at the file and line
+// it's about, through the preprocessor's line table when there is one
+func (e *ShaderCompileError) Diagnostics() []Diagnostic {
+	lines := strings.Split(strings.TrimRight(e.Source, "\x00"), "\n")
+
+	diagnostics := ParseInfoLog(e.InfoLog)
+	for i := range diagnostics {
+		d := &diagnostics[i]
+		d.Stage = e.Stage
+		d.File = e.Stage
+		if d.Line < 1 {
+			continue
+		}
+		if d.Line <= len(lines) {
+			d.Text = lines[d.Line-1]
+		}
+		if e.Processed != nil {
+			if origin := e.Processed.Origin(d.Line); origin.File >= 0 {
+				d.File = e.Processed.Files[origin.File]
+				d.Line = origin.Line
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// Format prints the message the way Go tools do, followed by the source
+// line and a caret under the column when the driver reported one
+//
+//	cube.vert:12:9: error: `oops' undeclared
+//		p = oops * 2.0;
+//		    ^
+func (d Diagnostic) Format() string {
+	var b strings.Builder
+	switch {
+	case d.Line > 0 && d.Column > 0:
+		fmt.Fprintf(&b, "%s:%d:%d: ", d.File, d.Line, d.Column)
+	case d.Line > 0:
+		fmt.Fprintf(&b, "%s:%d: ", d.File, d.Line)
+	case d.File != "":
+		fmt.Fprintf(&b, "%s: ", d.File)
+	}
+	fmt.Fprintf(&b, "%s: %s", d.Severity, d.Message)
+
+	if d.Text != "" {
+		b.WriteString("\n\t" + d.Text)
+		if d.Column > 0 {
+			// Mesa points one column early on semantic errors, at the
+			// space before the token, so step onto the token itself
+			column := d.Column
+			for column <= len(d.Text) && d.Text[column-1] == ' ' {
+				column++
+			}
+			// Keep the tabs so the caret lines up under indented code
+			var pad strings.Builder
+			for i, r := range d.Text {
+				if i >= column-1 {
+					break
+				}
+				if r == '\t' {
+					pad.WriteRune('\t')
+				} else {
+					pad.WriteRune(' ')
+				}
+			}
+			b.WriteString("\n\t" + pad.String() + "^")
+		}
+	}
+
+	return b.String()
+}
+
+// Format is every diagnostic formatted, the readable version of Error
+func (e *ShaderCompileError) Format() string {
+	var parts []string
+	for _, d := range e.Diagnostics() {
+		parts = append(parts, d.Format())
+	}
+	if len(parts) == 0 {
+		return e.Error()
+	}
+
+	return e.Stage + " shader compile error:\n" + strings.Join(parts, "\n")
+}
+
+// FormatShaderError is ShaderCompileError.Format for anything that wraps
+// one and err.Error() for everything else, for printing SetProgram errors
+func FormatShaderError(err error) string {
+	var compileErr *ShaderCompileError
+	if errors.As(err, &compileErr) {
+		return compileErr.Format()
+	}
+
+	return err.Error()
+}
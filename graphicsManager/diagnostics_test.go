@@ -0,0 +1,72 @@
+package graphicsManager
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInfoLog(t *testing.T) {
+	mesa := ParseInfoLog("0:4(12): error: `oops' undeclared\n0:7(3): warning: unused variable `x'\n0:1(10): preprocessor error: syntax error\n")
+	assert.Equal(t, []Diagnostic{
+		{Line: 4, Column: 12, Severity: SeverityError, Message: "`oops' undeclared"},
+		{Line: 7, Column: 3, Severity: SeverityWarning, Message: "unused variable `x'"},
+		{Line: 1, Column: 10, Severity: SeverityError, Message: "syntax error"},
+	}, mesa)
+
+	nvidia := ParseInfoLog("0(17) : error C1008: undefined variable \"oops\"\n0(3) : warning C7050: \"x\" might be used before being initialized\n")
+	assert.Equal(t, []Diagnostic{
+		{Line: 17, Severity: SeverityError, Message: "C1008: undefined variable \"oops\""},
+		{Line: 3, Severity: SeverityWarning, Message: "C7050: \"x\" might be used before being initialized"},
+	}, nvidia)
+
+	amd := ParseInfoLog("ERROR: 0:17: 'oops' : undeclared identifier\r\nWARNING: 0:2: extension not supported\r\nERROR: 1 compilation errors.  No code generated.\r\n\x00")
+	assert.Equal(t, []Diagnostic{
+		{Line: 17, Severity: SeverityError, Message: "'oops' : undeclared identifier"},
+		{Line: 2, Severity: SeverityWarning, Message: "extension not supported"},
+	}, amd)
+
+	// Anything else is kept, attached to the message before it
+	other := ParseInfoLog("Vertex shader failed to compile with the following errors:\n0:2(1): error: bad\n  more detail\n")
+	if assert.Len(t, other, 2) {
+		assert.Equal(t, 0, other[0].Line)
+		assert.Equal(t, "bad\nmore detail", other[1].Message)
+	}
+}
+
+func TestShaderCompileError_Diagnostics(t *testing.T) {
+	err := &ShaderCompileError{
+		Stage:   "vertex",
+		Source:  "#version 410\nvoid main() {\n\t  float x = oops;\n}\n\x00",
+		InfoLog: "0:3(13): error: `oops' undeclared\n",
+	}
+	diagnostics := err.Diagnostics()
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, Diagnostic{Stage: "vertex", File: "vertex", Line: 3, Column: 13, Message: "`oops' undeclared", Text: "\t  float x = oops;"}, diagnostics[0])
+	}
+	// The caret keeps the tab and lands on the token, not the space Mesa points at
+	assert.Equal(t, "vertex:3:13: error: `oops' undeclared\n\t\t  float x = oops;\n\t\t            ^", diagnostics[0].Format())
+	assert.Equal(t, "vertex shader compile error:\n"+diagnostics[0].Format(), FormatShaderError(err))
+	assert.Equal(t, assert.AnError.Error(), FormatShaderError(assert.AnError))
+}
+
+func TestShaderCompileError_DiagnosticsThroughIncludes(t *testing.T) {
+	fsys := fstest.MapFS{"bad.glsl": {Data: []byte("// one\nfloat g() {\n  return oops;\n}\n")}}
+	rec := &RecordingBackend{FailShaderCompile: true, InfoLog: "0:4(9): error: `oops' undeclared\n0(5) : error C0000: syntax error\n"}
+	manager := GLManager{Backend: rec, Preprocessor: NewPreprocessor(fsys)}
+	manager.VS = "#include \"bad.glsl\"\nvoid main() {}\n"
+	manager.FS = "void main() {}\n"
+
+	err := manager.SetProgram()
+	var compileErr *ShaderCompileError
+	if assert.ErrorAs(t, err, &compileErr) {
+		diagnostics := compileErr.Diagnostics()
+		if assert.Len(t, diagnostics, 2) {
+			assert.Equal(t, "bad.glsl", diagnostics[0].File)
+			assert.Equal(t, 3, diagnostics[0].Line)
+			assert.Equal(t, "  return oops;", diagnostics[0].Text)
+			assert.Equal(t, "bad.glsl:4: error: C0000: syntax error\n\t}", diagnostics[1].Format())
+		}
+	}
+}
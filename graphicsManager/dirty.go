@@ -0,0 +1,242 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MaxDirtyRanges is how many separate ranges a stream keeps before they are
+// merged into one covering range, past that the extra BufferSubData calls
+// cost more than the bytes they save
+var MaxDirtyRanges = 64
+
+// DirtyRange is a half open span of vertices [Start, End) that changed
+type DirtyRange struct {
+	Start, End int
+}
+
+func (r DirtyRange) Len() int {
+	return r.End - r.Start
+}
+
+// markChanged compares a stream before and after Set. A new length means
+// the buffer has to be reallocated, the same backing array means the
+// caller edited it in place and we can't tell what changed.
+func (s *VertexStorage) markChanged(name string, old, data []mgl32.Vec4) {
+	switch {
+	case len(old) != len(data):
+		s.markResized(name)
+	case len(data) == 0:
+	case &old[0] == &data[0]:
+		s.MarkDirty(name, 0, len(data))
+	default:
+		start := -1
+		for i := range data {
+			if old[i] != data[i] {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				s.MarkDirty(name, start, i)
+				start = -1
+			}
+		}
+		if start >= 0 {
+			s.MarkDirty(name, start, len(data))
+		}
+	}
+}
+
+func (s *VertexStorage) markResized(name string) {
+	if s.resized == nil {
+		s.resized = map[string]bool{}
+	}
+	s.resized[name] = true
+}
+
+// MarkDirty records that vertices [start, end) of a stream changed, for
+// callers that write into Stream's slice directly
+func (s *VertexStorage) MarkDirty(name string, start, end int) {
+	name = streamName(name)
+	if end <= start {
+		return
+	}
+	if s.dirty == nil {
+		s.dirty = map[string][]DirtyRange{}
+	}
+	s.dirty[name] = mergeRanges(append(s.dirty[name], DirtyRange{start, end}))
+}
+
+// Update writes values into a stream starting at vertex start and marks
+// just that span dirty. The stream has to be long enough already.
+func (s *VertexStorage) Update(name string, start int, values []mgl32.Vec4) error {
+	name = streamName(name)
+	stream, ok := s.streams[name]
+	if !ok {
+		return fmt.Errorf("%w: no stream %q", ErrInvalidStorageSelection, name)
+	}
+	if start < 0 || start+len(values) > len(stream) {
+		return fmt.Errorf("%w: update [%d, %d) outside %s's %d vertices", ErrStreamLength, start, start+len(values), name, len(stream))
+	}
+	copy(stream[start:], values)
+	s.MarkDirty(name, start, start+len(values))
+
+	return nil
+}
+
+// DirtyRanges is the sorted, merged list of changed spans of a stream
+func (s *VertexStorage) DirtyRanges(name string) []DirtyRange {
+	return append([]DirtyRange(nil), s.dirty[streamName(name)]...)
+}
+
+// Resized reports whether a stream changed length since the last upload
+func (s *VertexStorage) Resized(name string) bool {
+	return s.resized[streamName(name)]
+}
+
+// Dirty reports whether anything changed since the last MarkClean
+func (s *VertexStorage) Dirty() bool {
+	return len(s.dirty) > 0 || len(s.resized) > 0
+}
+
+// MarkClean forgets every change, called once the GPU copy is up to date
+func (s *VertexStorage) MarkClean() {
+	s.dirty, s.resized = nil, nil
+}
+
+// mergeRanges sorts and joins overlapping or touching ranges, collapsing
+// to one range when there are more than MaxDirtyRanges
+func mergeRanges(ranges []DirtyRange) []DirtyRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	if len(merged) > MaxDirtyRanges {
+		merged = []DirtyRange{{merged[0].Start, merged[len(merged)-1].End}}
+	}
+
+	return merged
+}
+
+// UpdateGeoVertices overwrites positions from start on, only those are re-uploaded
+func (glm *GLManager) UpdateGeoVertices(start int, values []mgl32.Vec4) error {
+	return glm.storage.Update(StreamPosition, start, values)
+}
+
+func (glm *GLManager) UpdateColorVertices(start int, values []mgl32.Vec4) error {
+	return glm.storage.Update(StreamColor, start, values)
+}
+
+// FlushDirty brings the GPU buffers up to date with the storage. Changed
+// spans go up with BufferSubData, a stream that changed length makes the
+// whole upload run again. RunLoop calls it before every frame.
+func (glm *GLManager) FlushDirty() error {
+	if !glm.storage.Dirty() || len(glm.vbos) == 0 {
+		return nil
+	}
+
+	if glm.layoutBound {
+		if err := glm.flushLayout(); err != nil {
+			return err
+		}
+	} else {
+		glm.flushVBOs()
+	}
+	glm.storage.MarkClean()
+
+	return nil
+}
+
+// flushVBOs handles what BindVBOs uploaded, position and color as vec4
+func (glm *GLManager) flushVBOs() {
+	if glm.storage.Resized(StreamPosition) || glm.storage.Resized(StreamColor) || len(glm.vbos) != 2 {
+		glm.BindVBOs()
+		return
+	}
+
+	b := glm.backend()
+	for i, name := range []string{StreamPosition, StreamColor} {
+		stream := glm.storage.Stream(name)
+		b.BindBuffer(gl.ARRAY_BUFFER, glm.vbos[i])
+		for _, r := range glm.storage.DirtyRanges(name) {
+			floats := Vec4sAsFloat32(stream[r.Start:min(r.End, len(stream))])
+			if len(floats) > 0 {
+				b.BufferSubData(gl.ARRAY_BUFFER, r.Start*16, 4*len(floats), gl.Ptr(floats))
+			}
+		}
+	}
+	b.BindBuffer(gl.ARRAY_BUFFER, 0)
+}
+
+// storageStream maps an attribute source onto the storage stream it reads
+func (glm *GLManager) storageStream(source string) string {
+	switch {
+	case glm.storage.Has(source):
+		return source
+	case source == PositionAttribute:
+		return StreamPosition
+	case source == ColorAttribute:
+		return StreamColor
+	}
+
+	return source
+}
+
+// flushLayout handles what BindLayout uploaded. Interleaved rows hold every
+// attribute, so the dirty spans of all streams are joined and whole rows
+// are rewritten once.
+func (glm *GLManager) flushLayout() error {
+	layout := glm.layout
+	for _, a := range layout.Attributes {
+		if glm.storage.Resized(glm.storageStream(a.source())) {
+			return glm.BindLayout()
+		}
+	}
+
+	b := glm.backend()
+	streams := glm.vertexStreams()
+	if layout.Interleaved {
+		var rows []DirtyRange
+		for _, a := range layout.Attributes {
+			rows = append(rows, glm.storage.DirtyRanges(glm.storageStream(a.source()))...)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		b.BindBuffer(gl.ARRAY_BUFFER, glm.vbos[0])
+		for _, r := range mergeRanges(rows) {
+			data, offset := layout.PackRange(streams, 0, r.Start, r.End)
+			if len(data) > 0 {
+				b.BufferSubData(gl.ARRAY_BUFFER, offset, len(data), gl.Ptr(data))
+			}
+		}
+	} else {
+		for i, a := range layout.Attributes {
+			ranges := glm.storage.DirtyRanges(glm.storageStream(a.source()))
+			if len(ranges) == 0 {
+				continue
+			}
+			b.BindBuffer(gl.ARRAY_BUFFER, glm.vbos[layout.Buffer(i)])
+			for _, r := range ranges {
+				data, offset := layout.PackRange(streams, i, r.Start, r.End)
+				if len(data) > 0 {
+					b.BufferSubData(gl.ARRAY_BUFFER, offset, len(data), gl.Ptr(data))
+				}
+			}
+		}
+	}
+	b.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	return nil
+}
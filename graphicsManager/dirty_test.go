@@ -0,0 +1,125 @@
+package graphicsManager
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func line(n int) []mgl32.Vec4 {
+	vertices := make([]mgl32.Vec4, n)
+	for i := range vertices {
+		vertices[i] = mgl32.Vec4{float32(i), 0, 0, 1}
+	}
+
+	return vertices
+}
+
+func floatAt(data []byte, i int) float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+}
+
+func TestVertexStorage_DirtyRanges(t *testing.T) {
+	var storage VertexStorage
+	storage.Set(StreamPosition, line(100))
+	assert.True(t, storage.Resized(StreamPosition))
+	storage.MarkClean()
+	assert.False(t, storage.Dirty())
+
+	// A new slice of the same length is diffed vertex by vertex
+	edited := line(100)
+	edited[10][1], edited[11][1], edited[50][1] = 1, 1, 1
+	storage.Set(StreamPosition, edited)
+	assert.Equal(t, []DirtyRange{{10, 12}, {50, 51}}, storage.DirtyRanges(StreamPosition))
+	assert.False(t, storage.Resized(StreamPosition))
+
+	// Touching spans merge
+	assert.NoError(t, storage.Update(StreamPosition, 12, []mgl32.Vec4{{}, {}}))
+	assert.Equal(t, []DirtyRange{{10, 14}, {50, 51}}, storage.DirtyRanges(StreamPosition))
+	assert.ErrorIs(t, storage.Update(StreamPosition, 99, []mgl32.Vec4{{}, {}}), ErrStreamLength)
+
+	// The same slice edited in place can't be diffed
+	edited[0][2] = 3
+	storage.Set(StreamPosition, edited)
+	assert.Equal(t, []DirtyRange{{0, 100}}, storage.DirtyRanges(StreamPosition))
+}
+
+func TestVertexStorage_DirtyRangeLimit(t *testing.T) {
+	var storage VertexStorage
+	storage.Set(StreamPosition, line(1000))
+	for i := 0; i <= MaxDirtyRanges; i++ {
+		storage.MarkDirty(StreamPosition, i*10, i*10+1)
+	}
+
+	assert.Equal(t, []DirtyRange{{0, MaxDirtyRanges*10 + 1}}, storage.DirtyRanges(StreamPosition))
+}
+
+func TestGLManager_FlushDirtyVBOs(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
+	manager.SetGeoVertices(line(1000))
+	manager.SetColorVertices(line(1000))
+	manager.BindVBOs()
+	rec.Reset()
+
+	assert.NoError(t, manager.UpdateGeoVertices(500, []mgl32.Vec4{{-1, -2, -3, 1}}))
+	assert.NoError(t, manager.FlushDirty())
+
+	// One vertex, 16 bytes, at its own offset and nothing else
+	calls := rec.CallsNamed("BufferSubData")
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, []any{uint32(gl.ARRAY_BUFFER), 500 * 16, 16}, calls[0].Args)
+	}
+	assert.Zero(t, rec.Count("BufferData"))
+	assert.Equal(t, float32(-2), floatAt(rec.BufferContents[manager.VBOs()[0]], 500*4+1))
+
+	// Nothing left to do on the next frame
+	rec.Reset()
+	assert.NoError(t, manager.FlushDirty())
+	assert.Empty(t, rec.Calls)
+
+	// Growing the mesh means a full upload
+	manager.SetGeoVertices(line(1001))
+	manager.SetColorVertices(line(1001))
+	assert.NoError(t, manager.FlushDirty())
+	assert.Equal(t, 2, rec.Count("BufferData"))
+}
+
+func TestGLManager_FlushDirtyLayout(t *testing.T) {
+	for _, interleaved := range []bool{true, false} {
+		rec := NewRecordingBackend()
+		manager := GLManager{Backend: rec, Program: 1}
+		manager.SetGeoVertices(line(100))
+		manager.SetColorVertices(line(100))
+		manager.SetVertexLayout(NewVertexLayout(interleaved,
+			VertexAttribute{Name: PositionAttribute, Components: 3, Type: gl.FLOAT},
+			VertexAttribute{Name: ColorAttribute, Components: 4, Type: gl.FLOAT},
+		))
+		assert.NoError(t, manager.BindLayout())
+		rec.Reset()
+
+		manager.UpdateGeoVertices(20, []mgl32.Vec4{{7, 8, 9, 1}})
+		manager.UpdateColorVertices(21, []mgl32.Vec4{{1, 1, 1, 1}})
+		assert.NoError(t, manager.FlushDirty())
+
+		calls := rec.CallsNamed("BufferSubData")
+		if interleaved {
+			// Rows 20 and 21 share a buffer and go up as one span of two rows
+			if assert.Len(t, calls, 1) {
+				assert.Equal(t, []any{uint32(gl.ARRAY_BUFFER), 20 * 28, 2 * 28}, calls[0].Args)
+			}
+			assert.Equal(t, float32(8), floatAt(rec.BufferContents[manager.VBOs()[0]], 20*7+1))
+		} else {
+			if assert.Len(t, calls, 2) {
+				assert.Equal(t, []any{uint32(gl.ARRAY_BUFFER), 20 * 12, 12}, calls[0].Args)
+				assert.Equal(t, []any{uint32(gl.ARRAY_BUFFER), 21 * 16, 16}, calls[1].Args)
+			}
+			assert.Equal(t, float32(8), floatAt(rec.BufferContents[manager.VBOs()[0]], 20*3+1))
+		}
+		assert.False(t, manager.Storage().Dirty())
+	}
+}
@@ -0,0 +1,423 @@
+package graphicsManager
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ErrorChecker wraps a Backend and calls GetError after every call, so a
+// GL error is reported by the call that caused it instead of whenever
+// somebody next looks. It's the debug mode for contexts before 4.3,
+// EnableDebug picks it when the callback isn't there.
+type ErrorChecker struct {
+	Backend
+	// Handler gets every error, DefaultDebugHandler when it's nil
+	Handler func(DebugMessage)
+}
+
+func NewErrorChecker(b Backend, handler func(DebugMessage)) *ErrorChecker {
+	return &ErrorChecker{Backend: b, Handler: handler}
+}
+
+// maxErrors bounds the GetError loop, a lost context can keep reporting
+const maxErrors = 16
+
+// check is deferred by every wrapped call with the call's name
+func (c *ErrorChecker) check(call string) {
+	for i := 0; i < maxErrors; i++ {
+		code := c.Backend.GetError()
+		if code == gl.NO_ERROR {
+			return
+		}
+		m := DebugMessage{
+			Source:   DebugSourceAPI,
+			Type:     DebugTypeError,
+			ID:       code,
+			Severity: DebugSeverityHigh,
+			Message:  GLErrorName(code) + " in " + call,
+			Call:     call,
+		}
+		m.File, m.Line = glCaller(2)
+		if c.Handler != nil {
+			c.Handler(m)
+		} else {
+			DefaultDebugHandler(m)
+		}
+	}
+}
+
+// GetError isn't checked, that would eat the errors it's asked for
+func (c *ErrorChecker) GetError() uint32 {
+	return c.Backend.GetError()
+}
+
+func (c *ErrorChecker) GenBuffer() uint32 {
+	defer c.check("GenBuffer")
+	return c.Backend.GenBuffer()
+}
+
+func (c *ErrorChecker) BindBuffer(target, buffer uint32) {
+	defer c.check("BindBuffer")
+	c.Backend.BindBuffer(target, buffer)
+}
+
+func (c *ErrorChecker) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	defer c.check("BufferData")
+	c.Backend.BufferData(target, size, data, usage)
+}
+
+func (c *ErrorChecker) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	defer c.check("BufferSubData")
+	c.Backend.BufferSubData(target, offset, size, data)
+}
+
+func (c *ErrorChecker) DeleteBuffer(buffer uint32) {
+	defer c.check("DeleteBuffer")
+	c.Backend.DeleteBuffer(buffer)
+}
+
+func (c *ErrorChecker) GenVertexArray() uint32 {
+	defer c.check("GenVertexArray")
+	return c.Backend.GenVertexArray()
+}
+
+func (c *ErrorChecker) BindVertexArray(vao uint32) {
+	defer c.check("BindVertexArray")
+	c.Backend.BindVertexArray(vao)
+}
+
+func (c *ErrorChecker) DeleteVertexArray(vao uint32) {
+	defer c.check("DeleteVertexArray")
+	c.Backend.DeleteVertexArray(vao)
+}
+
+func (c *ErrorChecker) EnableVertexAttribArray(index uint32) {
+	defer c.check("EnableVertexAttribArray")
+	c.Backend.EnableVertexAttribArray(index)
+}
+
+func (c *ErrorChecker) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uintptr) {
+	defer c.check("VertexAttribPointer")
+	c.Backend.VertexAttribPointer(index, size, xtype, normalized, stride, offset)
+}
+
+func (c *ErrorChecker) VertexAttribDivisor(index, divisor uint32) {
+	defer c.check("VertexAttribDivisor")
+	c.Backend.VertexAttribDivisor(index, divisor)
+}
+
+func (c *ErrorChecker) CreateShader(shaderType uint32) uint32 {
+	defer c.check("CreateShader")
+	return c.Backend.CreateShader(shaderType)
+}
+
+func (c *ErrorChecker) ShaderSource(shader uint32, source string) {
+	defer c.check("ShaderSource")
+	c.Backend.ShaderSource(shader, source)
+}
+
+func (c *ErrorChecker) CompileShader(shader uint32) {
+	defer c.check("CompileShader")
+	c.Backend.CompileShader(shader)
+}
+
+func (c *ErrorChecker) GetShaderiv(shader, pname uint32) int32 {
+	defer c.check("GetShaderiv")
+	return c.Backend.GetShaderiv(shader, pname)
+}
+
+func (c *ErrorChecker) GetShaderInfoLog(shader uint32) string {
+	defer c.check("GetShaderInfoLog")
+	return c.Backend.GetShaderInfoLog(shader)
+}
+
+func (c *ErrorChecker) DeleteShader(shader uint32) {
+	defer c.check("DeleteShader")
+	c.Backend.DeleteShader(shader)
+}
+
+func (c *ErrorChecker) CreateProgram() uint32 {
+	defer c.check("CreateProgram")
+	return c.Backend.CreateProgram()
+}
+
+func (c *ErrorChecker) AttachShader(program, shader uint32) {
+	defer c.check("AttachShader")
+	c.Backend.AttachShader(program, shader)
+}
+
+func (c *ErrorChecker) LinkProgram(program uint32) {
+	defer c.check("LinkProgram")
+	c.Backend.LinkProgram(program)
+}
+
+func (c *ErrorChecker) GetProgramiv(program, pname uint32) int32 {
+	defer c.check("GetProgramiv")
+	return c.Backend.GetProgramiv(program, pname)
+}
+
+func (c *ErrorChecker) GetProgramInfoLog(program uint32) string {
+	defer c.check("GetProgramInfoLog")
+	return c.Backend.GetProgramInfoLog(program)
+}
+
+func (c *ErrorChecker) UseProgram(program uint32) {
+	defer c.check("UseProgram")
+	c.Backend.UseProgram(program)
+}
+
+func (c *ErrorChecker) DeleteProgram(program uint32) {
+	defer c.check("DeleteProgram")
+	c.Backend.DeleteProgram(program)
+}
+
+func (c *ErrorChecker) GetAttribLocation(program uint32, name string) int32 {
+	defer c.check("GetAttribLocation")
+	return c.Backend.GetAttribLocation(program, name)
+}
+
+func (c *ErrorChecker) GetActiveUniform(program, index uint32) (name string, size int32, xtype uint32) {
+	defer c.check("GetActiveUniform")
+	return c.Backend.GetActiveUniform(program, index)
+}
+
+func (c *ErrorChecker) GetActiveAttrib(program, index uint32) (name string, size int32, xtype uint32) {
+	defer c.check("GetActiveAttrib")
+	return c.Backend.GetActiveAttrib(program, index)
+}
+
+func (c *ErrorChecker) PatchParameteri(pname uint32, value int32) {
+	defer c.check("PatchParameteri")
+	c.Backend.PatchParameteri(pname, value)
+}
+
+func (c *ErrorChecker) DispatchCompute(x, y, z uint32) error {
+	defer c.check("DispatchCompute")
+	return c.Backend.DispatchCompute(x, y, z)
+}
+
+func (c *ErrorChecker) MemoryBarrier(barriers uint32) error {
+	defer c.check("MemoryBarrier")
+	return c.Backend.MemoryBarrier(barriers)
+}
+
+func (c *ErrorChecker) BindBufferBase(target, index, buffer uint32) {
+	defer c.check("BindBufferBase")
+	c.Backend.BindBufferBase(target, index, buffer)
+}
+
+func (c *ErrorChecker) GetUniformLocation(program uint32, name string) int32 {
+	defer c.check("GetUniformLocation")
+	return c.Backend.GetUniformLocation(program, name)
+}
+
+func (c *ErrorChecker) Uniform1i(location int32, v int32) {
+	defer c.check("Uniform1i")
+	c.Backend.Uniform1i(location, v)
+}
+
+func (c *ErrorChecker) Uniform1f(location int32, v float32) {
+	defer c.check("Uniform1f")
+	c.Backend.Uniform1f(location, v)
+}
+
+func (c *ErrorChecker) Uniform1iv(location int32, v []int32) {
+	defer c.check("Uniform1iv")
+	c.Backend.Uniform1iv(location, v)
+}
+
+func (c *ErrorChecker) Uniform1fv(location int32, v []float32) {
+	defer c.check("Uniform1fv")
+	c.Backend.Uniform1fv(location, v)
+}
+
+func (c *ErrorChecker) Uniform2fv(location int32, v []float32) {
+	defer c.check("Uniform2fv")
+	c.Backend.Uniform2fv(location, v)
+}
+
+func (c *ErrorChecker) Uniform3fv(location int32, v []float32) {
+	defer c.check("Uniform3fv")
+	c.Backend.Uniform3fv(location, v)
+}
+
+func (c *ErrorChecker) Uniform4fv(location int32, v []float32) {
+	defer c.check("Uniform4fv")
+	c.Backend.Uniform4fv(location, v)
+}
+
+func (c *ErrorChecker) UniformMatrix3fv(location int32, transpose bool, v []float32) {
+	defer c.check("UniformMatrix3fv")
+	c.Backend.UniformMatrix3fv(location, transpose, v)
+}
+
+func (c *ErrorChecker) UniformMatrix4fv(location int32, transpose bool, v []float32) {
+	defer c.check("UniformMatrix4fv")
+	c.Backend.UniformMatrix4fv(location, transpose, v)
+}
+
+func (c *ErrorChecker) GenTexture() uint32 {
+	defer c.check("GenTexture")
+	return c.Backend.GenTexture()
+}
+
+func (c *ErrorChecker) BindTexture(target, texture uint32) {
+	defer c.check("BindTexture")
+	c.Backend.BindTexture(target, texture)
+}
+
+func (c *ErrorChecker) TexImage2D(target uint32, level, internalFormat, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	defer c.check("TexImage2D")
+	c.Backend.TexImage2D(target, level, internalFormat, width, height, format, xtype, pixels)
+}
+
+func (c *ErrorChecker) TexParameteri(target, pname uint32, param int32) {
+	defer c.check("TexParameteri")
+	c.Backend.TexParameteri(target, pname, param)
+}
+
+func (c *ErrorChecker) DeleteTexture(texture uint32) {
+	defer c.check("DeleteTexture")
+	c.Backend.DeleteTexture(texture)
+}
+
+func (c *ErrorChecker) GenFramebuffer() uint32 {
+	defer c.check("GenFramebuffer")
+	return c.Backend.GenFramebuffer()
+}
+
+func (c *ErrorChecker) BindFramebuffer(target, framebuffer uint32) {
+	defer c.check("BindFramebuffer")
+	c.Backend.BindFramebuffer(target, framebuffer)
+}
+
+func (c *ErrorChecker) FramebufferTexture2D(target, attachment, texTarget, texture uint32, level int32) {
+	defer c.check("FramebufferTexture2D")
+	c.Backend.FramebufferTexture2D(target, attachment, texTarget, texture, level)
+}
+
+func (c *ErrorChecker) FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer uint32) {
+	defer c.check("FramebufferRenderbuffer")
+	c.Backend.FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer)
+}
+
+func (c *ErrorChecker) CheckFramebufferStatus(target uint32) uint32 {
+	defer c.check("CheckFramebufferStatus")
+	return c.Backend.CheckFramebufferStatus(target)
+}
+
+func (c *ErrorChecker) DeleteFramebuffer(framebuffer uint32) {
+	defer c.check("DeleteFramebuffer")
+	c.Backend.DeleteFramebuffer(framebuffer)
+}
+
+func (c *ErrorChecker) GenRenderbuffer() uint32 {
+	defer c.check("GenRenderbuffer")
+	return c.Backend.GenRenderbuffer()
+}
+
+func (c *ErrorChecker) BindRenderbuffer(target, renderbuffer uint32) {
+	defer c.check("BindRenderbuffer")
+	c.Backend.BindRenderbuffer(target, renderbuffer)
+}
+
+func (c *ErrorChecker) RenderbufferStorage(target, internalFormat uint32, width, height int32) {
+	defer c.check("RenderbufferStorage")
+	c.Backend.RenderbufferStorage(target, internalFormat, width, height)
+}
+
+func (c *ErrorChecker) DeleteRenderbuffer(renderbuffer uint32) {
+	defer c.check("DeleteRenderbuffer")
+	c.Backend.DeleteRenderbuffer(renderbuffer)
+}
+
+func (c *ErrorChecker) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	defer c.check("ReadPixels")
+	c.Backend.ReadPixels(x, y, width, height, format, xtype, pixels)
+}
+
+func (c *ErrorChecker) FenceSync(condition, flags uint32) uintptr {
+	defer c.check("FenceSync")
+	return c.Backend.FenceSync(condition, flags)
+}
+
+func (c *ErrorChecker) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	defer c.check("ClientWaitSync")
+	return c.Backend.ClientWaitSync(sync, flags, timeout)
+}
+
+func (c *ErrorChecker) DeleteSync(sync uintptr) {
+	defer c.check("DeleteSync")
+	c.Backend.DeleteSync(sync)
+}
+
+func (c *ErrorChecker) Enable(capability uint32) {
+	defer c.check("Enable")
+	c.Backend.Enable(capability)
+}
+
+func (c *ErrorChecker) Disable(capability uint32) {
+	defer c.check("Disable")
+	c.Backend.Disable(capability)
+}
+
+func (c *ErrorChecker) ClearColor(r, g, b, a float32) {
+	defer c.check("ClearColor")
+	c.Backend.ClearColor(r, g, b, a)
+}
+
+func (c *ErrorChecker) Clear(mask uint32) {
+	defer c.check("Clear")
+	c.Backend.Clear(mask)
+}
+
+func (c *ErrorChecker) Viewport(x, y, width, height int32) {
+	defer c.check("Viewport")
+	c.Backend.Viewport(x, y, width, height)
+}
+
+func (c *ErrorChecker) GetViewport() (x, y, width, height int32) {
+	defer c.check("GetViewport")
+	return c.Backend.GetViewport()
+}
+
+func (c *ErrorChecker) DrawArrays(mode uint32, first, count int32) {
+	defer c.check("DrawArrays")
+	c.Backend.DrawArrays(mode, first, count)
+}
+
+func (c *ErrorChecker) DrawElements(mode uint32, count int32, xtype uint32, offset uintptr) {
+	defer c.check("DrawElements")
+	c.Backend.DrawElements(mode, count, xtype, offset)
+}
+
+func (c *ErrorChecker) PrimitiveRestartIndex(index uint32) {
+	defer c.check("PrimitiveRestartIndex")
+	c.Backend.PrimitiveRestartIndex(index)
+}
+
+func (c *ErrorChecker) GetString(name uint32) string {
+	defer c.check("GetString")
+	return c.Backend.GetString(name)
+}
+
+func (c *ErrorChecker) GetStringi(name, index uint32) string {
+	defer c.check("GetStringi")
+	return c.Backend.GetStringi(name, index)
+}
+
+func (c *ErrorChecker) GetIntegerv(pname uint32) int32 {
+	defer c.check("GetIntegerv")
+	return c.Backend.GetIntegerv(pname)
+}
+
+func (c *ErrorChecker) DebugMessageCallback(callback func(DebugMessage)) error {
+	defer c.check("DebugMessageCallback")
+	return c.Backend.DebugMessageCallback(callback)
+}
+
+func (c *ErrorChecker) DebugMessageControl(source, xtype, severity uint32, enabled bool) error {
+	defer c.check("DebugMessageControl")
+	return c.Backend.DebugMessageControl(source, xtype, severity, enabled)
+}
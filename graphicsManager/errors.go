@@ -0,0 +1,109 @@
+package graphicsManager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrInvalidConfig is returned when an option or size is out of range
+	ErrInvalidConfig = errors.New("invalid GLManager configuration")
+
+	// ErrUnsupportedShaderType is returned for a stage name SetShaderSource doesn't know
+	ErrUnsupportedShaderType = errors.New("unsupported shader type")
+
+	// ErrInvalidStorageSelection is returned for a storage name PutSlice doesn't know
+	ErrInvalidStorageSelection = errors.New("invalid storage selection")
+
+	// ErrStreamLength is returned when vertex streams disagree on the vertex count
+	ErrStreamLength = errors.New("vertex stream lengths don't match")
+
+	// ErrNoProgram is returned when a program is needed but none was linked
+	ErrNoProgram = errors.New("no shader program, call SetProgram first")
+
+	// ErrNoVertexArray is returned when a VAO is needed before BindVAOs ran
+	ErrNoVertexArray = errors.New("no vertex array, call BindVAOs first")
+
+	// ErrInvalidIndexType is returned for an index type SetIndexType can't use
+	ErrInvalidIndexType = errors.New("invalid index type")
+
+	// ErrInvalidLayout is returned when a vertex layout can't pack the storage
+	ErrInvalidLayout = errors.New("invalid vertex layout")
+
+	// ErrFenceTimeout is returned when the GPU holds a ring region too long
+	ErrFenceTimeout = errors.New("timed out waiting for a buffer fence")
+
+	// ErrNoContext is returned when a GL context is needed but the manager has none
+	ErrNoContext = errors.New("GLManager has no context")
+
+	// ErrUnknownUniform is returned for a uniform the program doesn't have,
+	// either a typo or the compiler optimized it away
+	ErrUnknownUniform = errors.New("unknown uniform")
+
+	// ErrUnknownAttribute is returned for an attribute the program doesn't have
+	ErrUnknownAttribute = errors.New("unknown attribute")
+
+	// ErrShaderInclude is returned when an #include can't be resolved
+	ErrShaderInclude = errors.New("shader include failed")
+
+	// ErrInvalidShader is returned when GLSL can't be parsed well enough to
+	// check, e.g. unbalanced braces
+	ErrInvalidShader = errors.New("invalid shader source")
+
+	// ErrNoGL43 is returned by the calls that need the 4.3 entry points
+	// (compute, barriers, debug output) when the driver doesn't have them
+	ErrNoGL43 = errors.New("OpenGL 4.3 entry points unavailable")
+)
+
+// ContextError is a failure while creating or initializing the GL context
+type ContextError struct {
+	Op  string
+	Err error
+}
+
+func (e *ContextError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// ShaderCompileError carries everything needed to find a broken shader
+type ShaderCompileError struct {
+	// Stage is the name SetShaderSource uses, "vertex", "geometry"...
+	Stage   string
+	Source  string
+	InfoLog string
+	// Processed is set when the source went through a Preprocessor, Source
+	// and the line numbers in InfoLog are then the expanded code
+	Processed *ProcessedSource
+}
+
+func (e *ShaderCompileError) Error() string {
+	log := e.InfoLog
+	if e.Processed != nil {
+		log = e.Processed.MapLog(log)
+	}
+
+	return fmt.Sprintf("%s shader compile error: %s", e.Stage, strings.TrimSpace(log))
+}
+
+// ProgramLinkError is a link failure with the program info log
+type ProgramLinkError struct {
+	InfoLog string
+}
+
+func (e *ProgramLinkError) Error() string {
+	return fmt.Sprintf("program link error: %s", strings.TrimSpace(e.InfoLog))
+}
+
+// FramebufferError is a framebuffer that came back incomplete
+type FramebufferError struct {
+	Status uint32
+}
+
+func (e *FramebufferError) Error() string {
+	return fmt.Sprintf("framebuffer incomplete: %s", GLEnumName(e.Status))
+}
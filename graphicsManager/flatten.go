@@ -0,0 +1,40 @@
+package graphicsManager
+
+import (
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// mgl32.Vec4 is a [4]float32 and Vec3 a [3]float32, so a slice of them is
+// already the tightly packed float array a buffer wants. These reinterpret
+// the memory instead of copying it, the result shares the backing array
+// so writes through either side show up in both.
+
+// Vec4sAsFloat32 views vertices as 4 floats each, nil for an empty slice
+func Vec4sAsFloat32(vertices []mgl32.Vec4) []float32 {
+	if len(vertices) == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*float32)(unsafe.Pointer(&vertices[0])), 4*len(vertices))
+}
+
+// Vec3sAsFloat32 views vertices as 3 floats each, nil for an empty slice
+func Vec3sAsFloat32(vertices []mgl32.Vec3) []float32 {
+	if len(vertices) == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*float32)(unsafe.Pointer(&vertices[0])), 3*len(vertices))
+}
+
+// Float32ToVec4 views every 4 floats as one Vec4, a trailing partial
+// vertex is left out
+func Float32ToVec4(floats []float32) []mgl32.Vec4 {
+	if len(floats) < 4 {
+		return nil
+	}
+
+	return unsafe.Slice((*mgl32.Vec4)(unsafe.Pointer(&floats[0])), len(floats)/4)
+}
@@ -0,0 +1,116 @@
+package graphicsManager
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+const millionVertices = 1 << 20
+
+func TestVec4sAsFloat32(t *testing.T) {
+	vertices := []mgl32.Vec4{{1, 2, 3, 4}, {5, 6, 7, 8}}
+
+	floats := Vec4sAsFloat32(vertices)
+	assert.Equal(t, []float32{1, 2, 3, 4, 5, 6, 7, 8}, floats)
+
+	// Same memory, not a copy
+	floats[5] = 60
+	assert.Equal(t, float32(60), vertices[1][1])
+	assert.Equal(t, vertices, Float32ToVec4(floats))
+	assert.Equal(t, unsafe.Pointer(&vertices[0]), unsafe.Pointer(&Float32ToVec4(floats)[0]))
+
+	assert.Equal(t, []float32{1, 2, 3, 4, 5, 6}, Vec3sAsFloat32([]mgl32.Vec3{{1, 2, 3}, {4, 5, 6}}))
+
+	assert.Nil(t, Vec4sAsFloat32(nil))
+	assert.Nil(t, Vec3sAsFloat32(nil))
+	assert.Nil(t, Float32ToVec4([]float32{1, 2, 3}))
+	assert.Len(t, Float32ToVec4([]float32{1, 2, 3, 4, 5}), 1)
+}
+
+// appendVec4Floats is the component by component flattening the storage
+// used before, kept here as the baseline for the benchmarks
+func appendVec4Floats(vec4Array []mgl32.Vec4) []float32 {
+	float32Array := make([]float32, 0, len(vec4Array)*4)
+	for _, vec := range vec4Array {
+		float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z(), vec.W())
+	}
+
+	return float32Array
+}
+
+// uploadBackend drops buffer uploads after reading the pointer, so the
+// benchmarks only see the cost of getting the data ready
+type uploadBackend struct {
+	*RecordingBackend
+	uploaded int
+}
+
+func (u *uploadBackend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	u.uploaded += size
+}
+
+func benchmarkMesh() []mgl32.Vec4 {
+	mesh := make([]mgl32.Vec4, millionVertices)
+	for i := range mesh {
+		mesh[i] = mgl32.Vec4{float32(i), 1, 2, 1}
+	}
+
+	return mesh
+}
+
+func BenchmarkFlattenCopy(b *testing.B) {
+	mesh := benchmarkMesh()
+	b.SetBytes(int64(16 * len(mesh)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = appendVec4Floats(mesh)
+	}
+}
+
+func BenchmarkFlattenView(b *testing.B) {
+	mesh := benchmarkMesh()
+	b.SetBytes(int64(16 * len(mesh)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = Vec4sAsFloat32(mesh)
+	}
+}
+
+func BenchmarkBindVBOsCopy(b *testing.B) {
+	backend := &uploadBackend{RecordingBackend: NewRecordingBackend()}
+	mesh := benchmarkMesh()
+	b.SetBytes(int64(2 * 16 * len(mesh)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		backend.Calls = nil
+		vbo := backend.GenBuffer()
+		uploadVbo(backend, vbo, appendVec4Floats(mesh))
+		uploadVbo(backend, vbo, appendVec4Floats(mesh))
+	}
+}
+
+func BenchmarkBindVBOsView(b *testing.B) {
+	backend := &uploadBackend{RecordingBackend: NewRecordingBackend()}
+	manager := GLManager{Backend: backend}
+	mesh := benchmarkMesh()
+	manager.SetGeoVertices(mesh)
+	manager.SetColorVertices(mesh)
+	manager.BindVBOs()
+	b.SetBytes(int64(2 * 16 * len(mesh)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		backend.Calls = nil
+		manager.BindVBOs()
+	}
+}
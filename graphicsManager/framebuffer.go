@@ -0,0 +1,191 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Framebuffer is an offscreen render target, an RGBA8 color texture and a
+// 24 bit depth renderbuffer, so scenes can be drawn without a window
+type Framebuffer struct {
+	Width, Height int
+
+	fbo          uint32
+	colorTexture uint32
+	depthBuffer  uint32
+	backend      func() Backend
+	// viewport is what Bind found, Unbind puts it back
+	viewport [4]int32
+}
+
+// NewFramebuffer creates a width x height framebuffer on the manager's backend
+func (glm *GLManager) NewFramebuffer(width, height int) (*Framebuffer, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w: framebuffer size must be positive, got %dx%d", ErrInvalidConfig, width, height)
+	}
+
+	b := glm.backend()
+	fb := &Framebuffer{Width: width, Height: height, backend: glm.backend}
+
+	fb.colorTexture = b.GenTexture()
+	b.BindTexture(gl.TEXTURE_2D, fb.colorTexture)
+	b.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	b.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	b.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	b.BindTexture(gl.TEXTURE_2D, 0)
+
+	fb.depthBuffer = b.GenRenderbuffer()
+	b.BindRenderbuffer(gl.RENDERBUFFER, fb.depthBuffer)
+	b.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT24, int32(width), int32(height))
+	b.BindRenderbuffer(gl.RENDERBUFFER, 0)
+
+	fb.fbo = b.GenFramebuffer()
+	b.BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	b.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, fb.colorTexture, 0)
+	b.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, fb.depthBuffer)
+
+	status := b.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	b.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		fb.Release()
+		return nil, &FramebufferError{Status: status}
+	}
+
+	return fb, nil
+}
+
+// FBO is the GL name of the framebuffer object
+func (fb *Framebuffer) FBO() uint32 {
+	return fb.fbo
+}
+
+// ColorTexture is the texture the color attachment renders into
+func (fb *Framebuffer) ColorTexture() uint32 {
+	return fb.colorTexture
+}
+
+// Bind makes the framebuffer the render target and matches the viewport to
+// it, the viewport it replaces is kept for Unbind
+func (fb *Framebuffer) Bind() {
+	x, y, width, height := fb.backend().GetViewport()
+	fb.viewport = [4]int32{x, y, width, height}
+	fb.backend().BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	fb.backend().Viewport(0, 0, int32(fb.Width), int32(fb.Height))
+}
+
+// Unbind goes back to the default framebuffer and the viewport from before Bind
+func (fb *Framebuffer) Unbind() {
+	fb.backend().BindFramebuffer(gl.FRAMEBUFFER, 0)
+	fb.backend().Viewport(fb.viewport[0], fb.viewport[1], fb.viewport[2], fb.viewport[3])
+}
+
+// ReadPixels reads the whole color attachment back
+func (fb *Framebuffer) ReadPixels() *image.RGBA {
+	fb.backend().BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	img := readPixels(fb.backend(), 0, 0, fb.Width, fb.Height)
+	fb.backend().BindFramebuffer(gl.FRAMEBUFFER, 0)
+
+	return img
+}
+
+// Screenshot writes the color attachment to path as a PNG
+func (fb *Framebuffer) Screenshot(path string) error {
+	return SavePNG(path, fb.ReadPixels())
+}
+
+// Release deletes the framebuffer and both attachments
+func (fb *Framebuffer) Release() {
+	if fb.fbo != 0 {
+		fb.backend().DeleteFramebuffer(fb.fbo)
+		fb.fbo = 0
+	}
+	if fb.colorTexture != 0 {
+		fb.backend().DeleteTexture(fb.colorTexture)
+		fb.colorTexture = 0
+	}
+	if fb.depthBuffer != 0 {
+		fb.backend().DeleteRenderbuffer(fb.depthBuffer)
+		fb.depthBuffer = 0
+	}
+}
+
+// ReadPixels reads a rectangle of the currently bound framebuffer. x and y
+// are in GL convention, from the bottom left, the image comes back upright.
+func (glm *GLManager) ReadPixels(x, y, width, height int) *image.RGBA {
+	return readPixels(glm.backend(), x, y, width, height)
+}
+
+// RenderToImage draws one frame with Render into a temporary framebuffer
+// of the given size and returns what it drew
+func (glm *GLManager) RenderToImage(width, height int) (*image.RGBA, error) {
+	fb, err := glm.NewFramebuffer(width, height)
+	if err != nil {
+		return nil, err
+	}
+	defer fb.Release()
+
+	fb.Bind()
+	glm.Render()
+	img := readPixels(fb.backend(), 0, 0, width, height)
+	fb.Unbind()
+
+	return img, nil
+}
+
+// Screenshot renders a frame the size of the context offscreen and saves it as a PNG
+func (glm *GLManager) Screenshot(path string) error {
+	if glm.Context == nil {
+		return fmt.Errorf("%w: screenshot needs one to size the frame, use RenderToImage instead", ErrNoContext)
+	}
+	width, height := glm.Context.FramebufferSize()
+
+	img, err := glm.RenderToImage(width, height)
+	if err != nil {
+		return err
+	}
+
+	return SavePNG(path, img)
+}
+
+// SavePNG writes img to path, the directory has to exist
+func SavePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func readPixels(b Backend, x, y, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == 0 || height == 0 {
+		return img
+	}
+	b.ReadPixels(int32(x), int32(y), int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, unsafe.Pointer(&img.Pix[0]))
+	flipRows(img)
+
+	return img
+}
+
+// flipRows turns GL's bottom row first layout into image's top row first
+func flipRows(img *image.RGBA) {
+	height := img.Bounds().Dy()
+	tmp := make([]byte, img.Stride)
+	for top, bottom := 0, height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*img.Stride : (top+1)*img.Stride]
+		b := img.Pix[bottom*img.Stride : (bottom+1)*img.Stride]
+		copy(tmp, a)
+		copy(a, b)
+		copy(b, tmp)
+	}
+}
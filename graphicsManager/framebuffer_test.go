@@ -0,0 +1,138 @@
+package graphicsManager
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// drawSoftware uploads positions and colors into a fresh VAO on sb and draws them as triangles
+func drawSoftware(sb *SoftwareBackend, positions, colors []mgl32.Vec4) {
+	vao := sb.GenVertexArray()
+	sb.BindVertexArray(vao)
+
+	for loc, data := range [][]float32{Vec4sAsFloat32(positions), Vec4sAsFloat32(colors)} {
+		vbo := sb.GenBuffer()
+		sb.BindBuffer(gl.ARRAY_BUFFER, vbo)
+		sb.BufferData(gl.ARRAY_BUFFER, 4*len(data), gl.Ptr(data), gl.STATIC_DRAW)
+		sb.VertexAttribPointer(uint32(loc), 4, gl.FLOAT, false, 0, 0)
+		sb.EnableVertexAttribArray(uint32(loc))
+	}
+
+	sb.DrawArrays(gl.TRIANGLES, 0, int32(len(positions)))
+}
+
+func TestGLManager_RenderToImage(t *testing.T) {
+	sb := NewSoftwareBackend(8, 8)
+	manager := GLManager{Backend: sb}
+
+	// Red across the top half of clip space
+	manager.RenderCall = func() {
+		sb.ClearColor(0, 0, 1, 1)
+		sb.Clear(gl.COLOR_BUFFER_BIT)
+		drawSoftware(sb, []mgl32.Vec4{
+			{-1, 0, 0, 1}, {1, 0, 0, 1}, {1, 1, 0, 1},
+			{-1, 0, 0, 1}, {1, 1, 0, 1}, {-1, 1, 0, 1},
+		}, []mgl32.Vec4{red, red, red, red, red, red})
+	}
+
+	img, err := manager.RenderToImage(8, 8)
+	assert.NoError(t, err)
+
+	// The image has to come back upright, not in GL's bottom up order
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(4, 1))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, img.RGBAAt(4, 6))
+
+	// The temporary framebuffer is gone and the default one is bound again
+	assert.Equal(t, 1, sb.Count("DeleteFramebuffer"))
+	assert.Equal(t, 1, sb.Count("DeleteTexture"))
+	assert.Equal(t, 1, sb.Count("DeleteRenderbuffer"))
+	assert.Zero(t, sb.BoundFramebuffer())
+
+	path := filepath.Join(t.TempDir(), "shot.png")
+	fb, err := manager.NewFramebuffer(8, 8)
+	assert.NoError(t, err)
+	assert.NoError(t, fb.Screenshot(path))
+	assert.FileExists(t, path)
+}
+
+func TestGLManager_RenderToImageAlpha(t *testing.T) {
+	sb := NewSoftwareBackend(4, 4)
+	manager := GLManager{Backend: sb}
+
+	// Only the RunLoop style callback, it gets the latest update
+	var alphas []float64
+	manager.RenderAlphaCall = func(alpha float64) {
+		alphas = append(alphas, alpha)
+		sb.ClearColor(1, 0, 0, 1)
+		sb.Clear(gl.COLOR_BUFFER_BIT)
+	}
+
+	img, err := manager.RenderToImage(4, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{1}, alphas)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(2, 2))
+}
+
+func TestGLManager_NewFramebufferIncomplete(t *testing.T) {
+	rec := &RecordingBackend{FramebufferStatus: gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT}
+	manager := GLManager{Backend: rec}
+
+	fb, err := manager.NewFramebuffer(16, 16)
+	assert.Nil(t, fb)
+	var fbErr *FramebufferError
+	if assert.ErrorAs(t, err, &fbErr) {
+		assert.Equal(t, uint32(gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT), fbErr.Status)
+	}
+	assert.Equal(t, 1, rec.Count("DeleteFramebuffer"))
+
+	_, err = manager.NewFramebuffer(0, 16)
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	// Without a window there is nothing to size a screenshot from
+	assert.ErrorIs(t, manager.Screenshot(filepath.Join(t.TempDir(), "x.png")), ErrNoContext)
+}
+
+func TestFramebuffer_UnbindRestoresViewport(t *testing.T) {
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec}
+	rec.Viewport(0, 0, 800, 600)
+
+	fb, err := manager.NewFramebuffer(16, 16)
+	assert.NoError(t, err)
+	fb.Bind()
+	x, y, width, height := rec.GetViewport()
+	assert.Equal(t, []int32{0, 0, 16, 16}, []int32{x, y, width, height})
+
+	// The window's viewport comes back with the default framebuffer
+	fb.Unbind()
+	x, y, width, height = rec.GetViewport()
+	assert.Equal(t, []int32{0, 0, 800, 600}, []int32{x, y, width, height})
+	assert.Zero(t, rec.BoundFramebuffer())
+}
+
+func TestGLManager_RenderToImageKeepsViewport(t *testing.T) {
+	sb := NewSoftwareBackend(8, 8)
+	manager := GLManager{Backend: sb}
+	manager.RenderCall = func() {}
+	sb.Viewport(2, 2, 4, 4)
+
+	_, err := manager.RenderToImage(16, 16)
+	assert.NoError(t, err)
+	x, y, width, height := sb.GetViewport()
+	assert.Equal(t, []int32{2, 2, 4, 4}, []int32{x, y, width, height})
+}
+
+func TestFlipRows(t *testing.T) {
+	sb := NewSoftwareBackend(1, 3)
+	sb.Rasterizer.Image().SetRGBA(0, 0, color.RGBA{1, 0, 0, 255})
+	sb.Rasterizer.Image().SetRGBA(0, 2, color.RGBA{3, 0, 0, 255})
+
+	img := readPixels(sb, 0, 0, 1, 3)
+	assert.Equal(t, uint8(1), img.RGBAAt(0, 0).R)
+	assert.Equal(t, uint8(3), img.RGBAAt(0, 2).R)
+}
@@ -0,0 +1,432 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	gl43 "github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// GLBackend is the real OpenGL 4.1 core backend. gl.Init has to have been
+// called on the thread that owns the context before any of these are used.
+type GLBackend struct{}
+
+func (GLBackend) GenBuffer() uint32 {
+	var vbo uint32
+	gl.GenBuffers(1, &vbo)
+	return vbo
+}
+
+func (GLBackend) BindBuffer(target, buffer uint32) {
+	gl.BindBuffer(target, buffer)
+}
+
+func (GLBackend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	gl.BufferData(target, size, data, usage)
+}
+
+func (GLBackend) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	gl.BufferSubData(target, offset, size, data)
+}
+
+func (GLBackend) DeleteBuffer(buffer uint32) {
+	gl.DeleteBuffers(1, &buffer)
+}
+
+func (GLBackend) GenVertexArray() uint32 {
+	var vao uint32
+	gl.GenVertexArrays(1, &vao)
+	return vao
+}
+
+func (GLBackend) BindVertexArray(vao uint32) {
+	gl.BindVertexArray(vao)
+}
+
+func (GLBackend) DeleteVertexArray(vao uint32) {
+	gl.DeleteVertexArrays(1, &vao)
+}
+
+func (GLBackend) EnableVertexAttribArray(index uint32) {
+	gl.EnableVertexAttribArray(index)
+}
+
+func (GLBackend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uintptr) {
+	gl.VertexAttribPointerWithOffset(index, size, xtype, normalized, stride, offset)
+}
+
+func (GLBackend) VertexAttribDivisor(index, divisor uint32) {
+	gl.VertexAttribDivisor(index, divisor)
+}
+
+func (GLBackend) CreateShader(shaderType uint32) uint32 {
+	return gl.CreateShader(shaderType)
+}
+
+func (GLBackend) ShaderSource(shader uint32, source string) {
+	csources, free := gl.Strs(nullTerminated(source))
+	gl.ShaderSource(shader, 1, csources, nil)
+	free()
+}
+
+func (GLBackend) CompileShader(shader uint32) {
+	gl.CompileShader(shader)
+}
+
+func (GLBackend) GetShaderiv(shader, pname uint32) int32 {
+	var value int32
+	gl.GetShaderiv(shader, pname, &value)
+	return value
+}
+
+func (b GLBackend) GetShaderInfoLog(shader uint32) string {
+	logLength := b.GetShaderiv(shader, gl.INFO_LOG_LENGTH)
+	if logLength == 0 {
+		return ""
+	}
+
+	log := make([]byte, logLength)
+	gl.GetShaderInfoLog(shader, logLength, nil, &log[0])
+
+	return strings.TrimRight(string(log), "\x00")
+}
+
+func (GLBackend) DeleteShader(shader uint32) {
+	gl.DeleteShader(shader)
+}
+
+func (GLBackend) CreateProgram() uint32 {
+	return gl.CreateProgram()
+}
+
+func (GLBackend) AttachShader(program, shader uint32) {
+	gl.AttachShader(program, shader)
+}
+
+func (GLBackend) LinkProgram(program uint32) {
+	gl.LinkProgram(program)
+}
+
+func (GLBackend) GetProgramiv(program, pname uint32) int32 {
+	var value int32
+	gl.GetProgramiv(program, pname, &value)
+	return value
+}
+
+func (b GLBackend) GetProgramInfoLog(program uint32) string {
+	logLength := b.GetProgramiv(program, gl.INFO_LOG_LENGTH)
+	if logLength == 0 {
+		return ""
+	}
+
+	log := make([]byte, logLength)
+	gl.GetProgramInfoLog(program, logLength, nil, &log[0])
+
+	return strings.TrimRight(string(log), "\x00")
+}
+
+func (GLBackend) UseProgram(program uint32) {
+	gl.UseProgram(program)
+}
+
+func (GLBackend) DeleteProgram(program uint32) {
+	gl.DeleteProgram(program)
+}
+
+func (GLBackend) GetAttribLocation(program uint32, name string) int32 {
+	cname, free := gl.Strs(nullTerminated(name))
+	defer free()
+	return gl.GetAttribLocation(program, *cname)
+}
+
+func (b GLBackend) GetActiveUniform(program, index uint32) (string, int32, uint32) {
+	name := make([]uint8, max(b.GetProgramiv(program, gl.ACTIVE_UNIFORM_MAX_LENGTH), 1))
+	var length, size int32
+	var xtype uint32
+	gl.GetActiveUniform(program, index, int32(len(name)), &length, &size, &xtype, &name[0])
+
+	return string(name[:length]), size, xtype
+}
+
+func (b GLBackend) GetActiveAttrib(program, index uint32) (string, int32, uint32) {
+	name := make([]uint8, max(b.GetProgramiv(program, gl.ACTIVE_ATTRIBUTE_MAX_LENGTH), 1))
+	var length, size int32
+	var xtype uint32
+	gl.GetActiveAttrib(program, index, int32(len(name)), &length, &size, &xtype, &name[0])
+
+	return string(name[:length]), size, xtype
+}
+
+func (GLBackend) PatchParameteri(pname uint32, value int32) {
+	gl.PatchParameteri(pname, value)
+}
+
+func (GLBackend) DispatchCompute(x, y, z uint32) error {
+	if err := initGL43("DispatchCompute"); err != nil {
+		return err
+	}
+	gl43.DispatchCompute(x, y, z)
+	return nil
+}
+
+func (GLBackend) MemoryBarrier(barriers uint32) error {
+	if err := initGL43("MemoryBarrier"); err != nil {
+		return err
+	}
+	gl43.MemoryBarrier(barriers)
+	return nil
+}
+
+func (GLBackend) BindBufferBase(target, index, buffer uint32) {
+	gl.BindBufferBase(target, index, buffer)
+}
+
+func (GLBackend) GetUniformLocation(program uint32, name string) int32 {
+	cname, free := gl.Strs(nullTerminated(name))
+	defer free()
+	return gl.GetUniformLocation(program, *cname)
+}
+
+func (GLBackend) Uniform1i(location int32, v int32) {
+	gl.Uniform1i(location, v)
+}
+
+func (GLBackend) Uniform1f(location int32, v float32) {
+	gl.Uniform1f(location, v)
+}
+
+func (GLBackend) Uniform1iv(location int32, v []int32) {
+	if len(v) > 0 {
+		gl.Uniform1iv(location, int32(len(v)), &v[0])
+	}
+}
+
+func (GLBackend) Uniform1fv(location int32, v []float32) {
+	if len(v) > 0 {
+		gl.Uniform1fv(location, int32(len(v)), &v[0])
+	}
+}
+
+func (GLBackend) Uniform2fv(location int32, v []float32) {
+	if len(v) >= 2 {
+		gl.Uniform2fv(location, int32(len(v)/2), &v[0])
+	}
+}
+
+func (GLBackend) Uniform3fv(location int32, v []float32) {
+	if len(v) >= 3 {
+		gl.Uniform3fv(location, int32(len(v)/3), &v[0])
+	}
+}
+
+func (GLBackend) Uniform4fv(location int32, v []float32) {
+	if len(v) >= 4 {
+		gl.Uniform4fv(location, int32(len(v)/4), &v[0])
+	}
+}
+
+func (GLBackend) UniformMatrix3fv(location int32, transpose bool, v []float32) {
+	if len(v) >= 9 {
+		gl.UniformMatrix3fv(location, int32(len(v)/9), transpose, &v[0])
+	}
+}
+
+func (GLBackend) UniformMatrix4fv(location int32, transpose bool, v []float32) {
+	if len(v) >= 16 {
+		gl.UniformMatrix4fv(location, int32(len(v)/16), transpose, &v[0])
+	}
+}
+
+func (GLBackend) GenTexture() uint32 {
+	var texture uint32
+	gl.GenTextures(1, &texture)
+	return texture
+}
+
+func (GLBackend) BindTexture(target, texture uint32) {
+	gl.BindTexture(target, texture)
+}
+
+func (GLBackend) TexImage2D(target uint32, level, internalFormat, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	gl.TexImage2D(target, level, internalFormat, width, height, 0, format, xtype, pixels)
+}
+
+func (GLBackend) TexParameteri(target, pname uint32, param int32) {
+	gl.TexParameteri(target, pname, param)
+}
+
+func (GLBackend) DeleteTexture(texture uint32) {
+	gl.DeleteTextures(1, &texture)
+}
+
+func (GLBackend) GenFramebuffer() uint32 {
+	var fbo uint32
+	gl.GenFramebuffers(1, &fbo)
+	return fbo
+}
+
+func (GLBackend) BindFramebuffer(target, framebuffer uint32) {
+	gl.BindFramebuffer(target, framebuffer)
+}
+
+func (GLBackend) FramebufferTexture2D(target, attachment, texTarget, texture uint32, level int32) {
+	gl.FramebufferTexture2D(target, attachment, texTarget, texture, level)
+}
+
+func (GLBackend) FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer uint32) {
+	gl.FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer)
+}
+
+func (GLBackend) CheckFramebufferStatus(target uint32) uint32 {
+	return gl.CheckFramebufferStatus(target)
+}
+
+func (GLBackend) DeleteFramebuffer(framebuffer uint32) {
+	gl.DeleteFramebuffers(1, &framebuffer)
+}
+
+func (GLBackend) GenRenderbuffer() uint32 {
+	var rbo uint32
+	gl.GenRenderbuffers(1, &rbo)
+	return rbo
+}
+
+func (GLBackend) BindRenderbuffer(target, renderbuffer uint32) {
+	gl.BindRenderbuffer(target, renderbuffer)
+}
+
+func (GLBackend) RenderbufferStorage(target, internalFormat uint32, width, height int32) {
+	gl.RenderbufferStorage(target, internalFormat, width, height)
+}
+
+func (GLBackend) DeleteRenderbuffer(renderbuffer uint32) {
+	gl.DeleteRenderbuffers(1, &renderbuffer)
+}
+
+func (GLBackend) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	// Rows are tightly packed in the images we hand out
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(x, y, width, height, format, xtype, pixels)
+}
+
+func (GLBackend) FenceSync(condition, flags uint32) uintptr {
+	return gl.FenceSync(condition, flags)
+}
+
+func (GLBackend) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	return gl.ClientWaitSync(sync, flags, timeout)
+}
+
+func (GLBackend) DeleteSync(sync uintptr) {
+	gl.DeleteSync(sync)
+}
+
+func (GLBackend) Enable(capability uint32) {
+	gl.Enable(capability)
+}
+
+func (GLBackend) Disable(capability uint32) {
+	gl.Disable(capability)
+}
+
+func (GLBackend) ClearColor(r, g, b, a float32) {
+	gl.ClearColor(r, g, b, a)
+}
+
+func (GLBackend) Clear(mask uint32) {
+	gl.Clear(mask)
+}
+
+func (GLBackend) Viewport(x, y, width, height int32) {
+	gl.Viewport(x, y, width, height)
+}
+
+func (GLBackend) GetViewport() (x, y, width, height int32) {
+	var v [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &v[0])
+	return v[0], v[1], v[2], v[3]
+}
+
+func (GLBackend) DrawArrays(mode uint32, first, count int32) {
+	gl.DrawArrays(mode, first, count)
+}
+
+func (GLBackend) DrawElements(mode uint32, count int32, xtype uint32, offset uintptr) {
+	gl.DrawElementsWithOffset(mode, count, xtype, offset)
+}
+
+func (GLBackend) PrimitiveRestartIndex(index uint32) {
+	gl.PrimitiveRestartIndex(index)
+}
+
+func (GLBackend) GetError() uint32 {
+	return gl.GetError()
+}
+
+func (GLBackend) GetString(name uint32) string {
+	return gl.GoStr(gl.GetString(name))
+}
+
+func (GLBackend) GetStringi(name, index uint32) string {
+	return gl.GoStr(gl.GetStringi(name, index))
+}
+
+func (GLBackend) GetIntegerv(pname uint32) int32 {
+	var v int32
+	gl.GetIntegerv(pname, &v)
+	return v
+}
+
+func (GLBackend) DebugMessageCallback(callback func(DebugMessage)) error {
+	if err := initGL43("DebugMessageCallback"); err != nil {
+		return err
+	}
+	if callback == nil {
+		gl43.DebugMessageCallback(nil, nil)
+		return nil
+	}
+	gl43.DebugMessageCallback(func(source, xtype, id, severity uint32, length int32, message string, userParam unsafe.Pointer) {
+		callback(DebugMessage{Source: source, Type: xtype, ID: id, Severity: DebugSeverity(severity), Message: strings.TrimSpace(message)})
+	}, nil)
+	return nil
+}
+
+func (GLBackend) DebugMessageControl(source, xtype, severity uint32, enabled bool) error {
+	if err := initGL43("DebugMessageControl"); err != nil {
+		return err
+	}
+	gl43.DebugMessageControl(source, xtype, severity, 0, nil, enabled)
+	return nil
+}
+
+// nullTerminated appends the NUL gl.Strs doesn't add, GL would otherwise
+// read past the end of the name or shader source
+func nullTerminated(s string) string {
+	if strings.HasSuffix(s, "\x00") {
+		return s
+	}
+
+	return s + "\x00"
+}
+
+var gl43Init struct {
+	once sync.Once
+	err  error
+}
+
+// initGL43 loads the 4.3 entry points the first time call needs them, the
+// 4.1 bindings gl.Init loads don't have them and a 4.1 context can't
+// provide them
+func initGL43(call string) error {
+	gl43Init.once.Do(func() {
+		gl43Init.err = gl43.Init()
+	})
+	if gl43Init.err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrNoGL43, call, gl43Init.err)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package graphicsManager
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// KHR_debug enums, the 4.1 bindings don't have them
+const (
+	DebugOutput            = 0x92E0
+	DebugOutputSynchronous = 0x8242
+	ContextFlags           = 0x821E
+	ContextFlagDebugBit    = 0x2
+
+	DebugSourceAPI            = 0x8246
+	DebugSourceWindowSystem   = 0x8247
+	DebugSourceShaderCompiler = 0x8248
+	DebugSourceThirdParty     = 0x8249
+	DebugSourceApplication    = 0x824A
+	DebugSourceOther          = 0x824B
+
+	DebugTypeError              = 0x824C
+	DebugTypeDeprecatedBehavior = 0x824D
+	DebugTypeUndefinedBehavior  = 0x824E
+	DebugTypePortability        = 0x824F
+	DebugTypePerformance        = 0x8250
+	DebugTypeOther              = 0x8251
+	DebugTypeMarker             = 0x8268
+	DebugTypePushGroup          = 0x8269
+	DebugTypePopGroup           = 0x826A
+)
+
+// glEnumNames are the enums that end up in error messages. Small values
+// mean different things depending on the argument (0 is NO_ERROR, POINTS,
+// FALSE...) so only GLErrorName names those.
+var glEnumNames = map[uint32]string{
+	gl.INVALID_ENUM:                  "GL_INVALID_ENUM",
+	gl.INVALID_VALUE:                 "GL_INVALID_VALUE",
+	gl.INVALID_OPERATION:             "GL_INVALID_OPERATION",
+	gl.STACK_OVERFLOW:                "GL_STACK_OVERFLOW",
+	gl.STACK_UNDERFLOW:               "GL_STACK_UNDERFLOW",
+	gl.OUT_OF_MEMORY:                 "GL_OUT_OF_MEMORY",
+	gl.INVALID_FRAMEBUFFER_OPERATION: "GL_INVALID_FRAMEBUFFER_OPERATION",
+	0x0507:                           "GL_CONTEXT_LOST",
+
+	gl.FRAMEBUFFER_COMPLETE:                      "GL_FRAMEBUFFER_COMPLETE",
+	gl.FRAMEBUFFER_UNDEFINED:                     "GL_FRAMEBUFFER_UNDEFINED",
+	gl.FRAMEBUFFER_INCOMPLETE_ATTACHMENT:         "GL_FRAMEBUFFER_INCOMPLETE_ATTACHMENT",
+	gl.FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT: "GL_FRAMEBUFFER_INCOMPLETE_MISSING_ATTACHMENT",
+	gl.FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER:        "GL_FRAMEBUFFER_INCOMPLETE_DRAW_BUFFER",
+	gl.FRAMEBUFFER_INCOMPLETE_READ_BUFFER:        "GL_FRAMEBUFFER_INCOMPLETE_READ_BUFFER",
+	gl.FRAMEBUFFER_UNSUPPORTED:                   "GL_FRAMEBUFFER_UNSUPPORTED",
+	gl.FRAMEBUFFER_INCOMPLETE_MULTISAMPLE:        "GL_FRAMEBUFFER_INCOMPLETE_MULTISAMPLE",
+	gl.FRAMEBUFFER_INCOMPLETE_LAYER_TARGETS:      "GL_FRAMEBUFFER_INCOMPLETE_LAYER_TARGETS",
+
+	gl.ALREADY_SIGNALED:    "GL_ALREADY_SIGNALED",
+	gl.TIMEOUT_EXPIRED:     "GL_TIMEOUT_EXPIRED",
+	gl.CONDITION_SATISFIED: "GL_CONDITION_SATISFIED",
+	gl.WAIT_FAILED:         "GL_WAIT_FAILED",
+
+	gl.BYTE:           "GL_BYTE",
+	gl.UNSIGNED_BYTE:  "GL_UNSIGNED_BYTE",
+	gl.SHORT:          "GL_SHORT",
+	gl.UNSIGNED_SHORT: "GL_UNSIGNED_SHORT",
+	gl.INT:            "GL_INT",
+	gl.UNSIGNED_INT:   "GL_UNSIGNED_INT",
+	gl.FLOAT:          "GL_FLOAT",
+	gl.DOUBLE:         "GL_DOUBLE",
+	gl.HALF_FLOAT:     "GL_HALF_FLOAT",
+
+	gl.VERTEX_SHADER:          "GL_VERTEX_SHADER",
+	gl.FRAGMENT_SHADER:        "GL_FRAGMENT_SHADER",
+	gl.GEOMETRY_SHADER:        "GL_GEOMETRY_SHADER",
+	gl.TESS_CONTROL_SHADER:    "GL_TESS_CONTROL_SHADER",
+	gl.TESS_EVALUATION_SHADER: "GL_TESS_EVALUATION_SHADER",
+	ComputeShader:             "GL_COMPUTE_SHADER",
+
+	gl.ARRAY_BUFFER:           "GL_ARRAY_BUFFER",
+	gl.ELEMENT_ARRAY_BUFFER:   "GL_ELEMENT_ARRAY_BUFFER",
+	gl.UNIFORM_BUFFER:         "GL_UNIFORM_BUFFER",
+	ShaderStorageBuffer:       "GL_SHADER_STORAGE_BUFFER",
+	gl.TEXTURE_2D:             "GL_TEXTURE_2D",
+	gl.TEXTURE_2D_MULTISAMPLE: "GL_TEXTURE_2D_MULTISAMPLE",
+	gl.FRAMEBUFFER:            "GL_FRAMEBUFFER",
+	gl.RENDERBUFFER:           "GL_RENDERBUFFER",
+	gl.STATIC_DRAW:            "GL_STATIC_DRAW",
+	gl.DYNAMIC_DRAW:           "GL_DYNAMIC_DRAW",
+	gl.STREAM_DRAW:            "GL_STREAM_DRAW",
+
+	gl.DEPTH_TEST:                    "GL_DEPTH_TEST",
+	gl.CULL_FACE:                     "GL_CULL_FACE",
+	gl.BLEND:                         "GL_BLEND",
+	gl.MULTISAMPLE:                   "GL_MULTISAMPLE",
+	gl.PRIMITIVE_RESTART:             "GL_PRIMITIVE_RESTART",
+	gl.PRIMITIVE_RESTART_FIXED_INDEX: "GL_PRIMITIVE_RESTART_FIXED_INDEX",
+	gl.PATCHES:                       "GL_PATCHES",
+
+	DebugOutput:            "GL_DEBUG_OUTPUT",
+	DebugOutputSynchronous: "GL_DEBUG_OUTPUT_SYNCHRONOUS",
+
+	DebugSourceAPI:            "GL_DEBUG_SOURCE_API",
+	DebugSourceWindowSystem:   "GL_DEBUG_SOURCE_WINDOW_SYSTEM",
+	DebugSourceShaderCompiler: "GL_DEBUG_SOURCE_SHADER_COMPILER",
+	DebugSourceThirdParty:     "GL_DEBUG_SOURCE_THIRD_PARTY",
+	DebugSourceApplication:    "GL_DEBUG_SOURCE_APPLICATION",
+	DebugSourceOther:          "GL_DEBUG_SOURCE_OTHER",
+
+	DebugTypeError:              "GL_DEBUG_TYPE_ERROR",
+	DebugTypeDeprecatedBehavior: "GL_DEBUG_TYPE_DEPRECATED_BEHAVIOR",
+	DebugTypeUndefinedBehavior:  "GL_DEBUG_TYPE_UNDEFINED_BEHAVIOR",
+	DebugTypePortability:        "GL_DEBUG_TYPE_PORTABILITY",
+	DebugTypePerformance:        "GL_DEBUG_TYPE_PERFORMANCE",
+	DebugTypeOther:              "GL_DEBUG_TYPE_OTHER",
+	DebugTypeMarker:             "GL_DEBUG_TYPE_MARKER",
+	DebugTypePushGroup:          "GL_DEBUG_TYPE_PUSH_GROUP",
+	DebugTypePopGroup:           "GL_DEBUG_TYPE_POP_GROUP",
+
+	uint32(DebugSeverityHigh):         "GL_DEBUG_SEVERITY_HIGH",
+	uint32(DebugSeverityMedium):       "GL_DEBUG_SEVERITY_MEDIUM",
+	uint32(DebugSeverityLow):          "GL_DEBUG_SEVERITY_LOW",
+	uint32(DebugSeverityNotification): "GL_DEBUG_SEVERITY_NOTIFICATION",
+}
+
+// GLEnumName is the GL_ name of an error, status, type or target enum,
+// or the value in hex when it isn't one of those
+func GLEnumName(value uint32) string {
+	if name, ok := glEnumNames[value]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%04X", value)
+}
+
+// GLErrorName names what GetError returned, GL_NO_ERROR included
+func GLErrorName(code uint32) string {
+	if code == gl.NO_ERROR {
+		return "GL_NO_ERROR"
+	}
+
+	return GLEnumName(code)
+}
@@ -1,8 +1,10 @@
 package graphicsManager
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,8 +20,13 @@ func TestGLManager_SetShaderSource(t *testing.T) {
 	manager.SetShaderSource("fragment_shader_code", "fragment")
 	assert.Equal(t, "fragment_shader_code", manager.FragmentShaderSource())
 
+	// Geometry and tessellation stages are accepted too
+	assert.NoError(t, manager.SetShaderSource("geometry_shader_code", "geometry"))
+	assert.Equal(t, "geometry_shader_code", manager.GS)
+
 	// Test setting an unsupported shader type
-	manager.SetShaderSource("unsupported_shader_code", "geometry")
+	err := manager.SetShaderSource("unsupported_shader_code", "mesh")
+	assert.ErrorIs(t, err, ErrUnsupportedShaderType)
 	assert.Equal(t, "fragment_shader_code", manager.FragmentShaderSource())
 	assert.Equal(t, "vertex_shader_code", manager.VertexShaderSource())
 }
@@ -59,29 +66,141 @@ func TestGLManager_SetFloat32Vertices(t *testing.T) {
 }
 
 func TestGLManager_BindProgram(t *testing.T) {
-	manager := GLManager{}
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
 
 	// Test binding program with a non-zero program value
 	manager.Program = 123
-	manager.BindProgram()
-	// Assert that the program is in use (not checking OpenGL state in this example)
+	assert.NoError(t, manager.BindProgram())
+	assert.Equal(t, uint32(123), rec.CurrentProgram())
+
+	// A zero program is never handed to the backend
+	rec.Reset()
+	manager.Program = 0
+	assert.ErrorIs(t, manager.BindProgram(), ErrNoProgram)
+	assert.Zero(t, rec.Count("UseProgram"))
 }
 
 func TestGLManager_BindVAO(t *testing.T) {
-	manager := GLManager{}
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
 
 	// Test binding VAO with a non-zero VBO value
 	manager.vbos = []uint32{456, 490}
 	manager.BindVAOs()
-	// Assert that the VAO is created and bound (not checking OpenGL state in this example)
+	assert.Equal(t, 2, rec.Count("GenVertexArray"))
+	assert.Len(t, manager.VAOs(), 2)
 }
 
 func TestGLManager_BindVBO(t *testing.T) {
-	manager := GLManager{}
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
+
+	manager.SetGeoVertices([]mgl32.Vec4{{1.0, 2.0, 3.0, 1.0}})
+	manager.SetColorVertices([]mgl32.Vec4{{1.0, 0.0, 0.0, 1.0}})
 
 	// Test binding VBO
 	manager.BindVBOs()
-	// Assert that the VBO is created and bound (not checking OpenGL state in this example)
+	assert.Equal(t, 2, rec.Count("GenBuffer"))
+	assert.Equal(t, 2, rec.Count("BufferData"))
+	assert.Len(t, manager.VBOs(), 2)
+
+	// Each buffer holds the flattened floats of its storage
+	geo := rec.BufferContents[manager.VBOs()[0]]
+	assert.Len(t, geo, 4*4)
+	assert.Equal(t, uint32(0), rec.BoundBuffer(gl.ARRAY_BUFFER))
+}
+
+func TestGLManager_NewProgram(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+
+	assert.NoError(t, manager.SetProgram())
+	assert.NotZero(t, manager.GetProgram())
+	assert.Equal(t, 2, rec.Count("CreateShader"))
+	assert.Equal(t, 2, rec.Count("AttachShader"))
+	assert.Equal(t, 1, rec.Count("LinkProgram"))
+
+	sources := rec.CallsNamed("ShaderSource")
+	assert.Equal(t, "vertex", sources[0].Args[1])
+	assert.Equal(t, "fragment", sources[1].Args[1])
+}
+
+func TestGLManager_RebindIsIdempotent(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
+
+	manager.SetGeoVertices([]mgl32.Vec4{{1.0, 2.0, 3.0, 1.0}})
+	manager.BindVBOs()
+	manager.BindVAOs()
+	vbos, vaos := manager.VBOs(), manager.VAOs()
+
+	// Rebuilding the geometry reuses the same buffers and vertex arrays
+	manager.SetGeoVertices([]mgl32.Vec4{{1.0, 2.0, 3.0, 1.0}, {4.0, 5.0, 6.0, 1.0}})
+	manager.BindVBOs()
+	manager.BindVAOs()
+	assert.Equal(t, vbos, manager.VBOs())
+	assert.Equal(t, vaos, manager.VAOs())
+	assert.Equal(t, 2, rec.Count("GenBuffer"))
+	assert.Equal(t, 2, rec.Count("GenVertexArray"))
+	assert.Len(t, rec.BufferContents[vbos[0]], 2*4*4)
+}
+
+func TestGLManager_Destroy(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+
+	assert.NoError(t, manager.SetProgram())
+	// Shader objects aren't needed once the program is linked
+	assert.Equal(t, 2, rec.Count("DeleteShader"))
+
+	// Replacing the program deletes the old one
+	first := manager.GetProgram()
+	assert.NoError(t, manager.SetProgram())
+	assert.Equal(t, first, rec.CallsNamed("DeleteProgram")[0].Args[0])
+
+	manager.SetGeoVertices([]mgl32.Vec4{{1.0, 2.0, 3.0, 1.0}})
+	manager.BindVBOs()
+	manager.BindVAOs()
+
+	manager.Destroy()
+	assert.Equal(t, 2, rec.Count("DeleteBuffer"))
+	assert.Equal(t, 2, rec.Count("DeleteVertexArray"))
+	assert.Equal(t, 2, rec.Count("DeleteProgram"))
+	assert.Empty(t, manager.VBOs())
+	assert.Empty(t, manager.VAOs())
+	assert.Zero(t, manager.GetProgram())
+
+	// A second Destroy has nothing left to free
+	rec.Reset()
+	manager.Destroy()
+	assert.Empty(t, rec.Calls)
+}
+
+func TestGLManager_NewProgramFailures(t *testing.T) {
+	rec := &RecordingBackend{FailShaderCompile: true, InfoLog: "0:1(1): error: syntax error"}
+	manager := GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+
+	err := manager.SetProgram()
+	var compileErr *ShaderCompileError
+	if assert.True(t, errors.As(err, &compileErr)) {
+		assert.Equal(t, "vertex", compileErr.Stage)
+		assert.Equal(t, "vertex", compileErr.Source)
+		assert.Equal(t, "0:1(1): error: syntax error", compileErr.InfoLog)
+	}
+	assert.Zero(t, manager.GetProgram())
+	assert.Zero(t, rec.Count("LinkProgram"))
+	assert.Equal(t, 1, rec.Count("DeleteShader"))
+
+	rec = &RecordingBackend{FailProgramLink: true}
+	manager = GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+
+	err = manager.SetProgram()
+	var linkErr *ProgramLinkError
+	assert.True(t, errors.As(err, &linkErr))
+	assert.Zero(t, manager.GetProgram())
+	assert.Equal(t, 1, rec.Count("GetProgramInfoLog"))
+	assert.Equal(t, 1, rec.Count("DeleteProgram"))
 }
 
 func TestGLManager_ConvertVec3ToFloat32(t *testing.T) {
@@ -119,7 +238,7 @@ func TestGLManager_ClearFloat32Vertices(t *testing.T) {
 
 	manager := GLManager{}
 
-	vertices := [][]float32{{1.0, 2.0, 3.0, 4.0, 5.0, 6.0}, {1.0, 3.0, 3.0, 5.0, 5.0, 6.0}}
+	vertices := []float32{1.0, 2.0, 3.0, 4.0, 5.0, 6.0}
 
 	manager.float32vertices = vertices
 	result := manager.float32vertices
@@ -129,3 +248,17 @@ func TestGLManager_ClearFloat32Vertices(t *testing.T) {
 	assert.Empty(t, manager.float32vertices)
 
 }
+
+func TestGLManager_PutSliceSelection(t *testing.T) {
+	manager := GLManager{}
+	manager.NewVec4Storage()
+
+	err := manager.PutSlice([]mgl32.Vec4{{1, 2, 3, 1}}, "")
+	assert.ErrorIs(t, err, ErrInvalidStorageSelection)
+
+	// The old selection names still land in the position and color streams
+	assert.NoError(t, manager.PutSlice([]mgl32.Vec4{{1, 2, 3, 1}}, "object"))
+	assert.NoError(t, manager.PutSlice([]mgl32.Vec4{{1, 0, 0, 1}}, "color"))
+	assert.Equal(t, []mgl32.Vec4{{1, 2, 3, 1}}, manager.Vec4Storage().ObjectVertices)
+	assert.Equal(t, []float32{1, 0, 0, 1}, manager.GetColorVertices())
+}
@@ -0,0 +1,659 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GLSLDeclaration is one in, out, uniform or buffer declaration at the
+// top level of a shader. Interface blocks come out as one declaration
+// with Block set and the members in Members.
+type GLSLDeclaration struct {
+	// Storage is "in", "out", "uniform" or "buffer". attribute and varying
+	// from older GLSL become in and out for the stage they're in.
+	Storage string
+	Type    string
+	// Name is empty for a block declared without an instance name
+	Name string
+	// Array holds the sizes between the brackets, "" for [], so
+	// vec4 v[3][] is ["3", ""]
+	Array []string
+	// Location is from layout(location = n), -1 without one
+	Location int
+	// Qualifiers are the rest, flat, patch, highp...
+	Qualifiers []string
+	Block      string
+	Members    []GLSLDeclaration
+	// Line and Column of the name, 1 based
+	Line   int
+	Column int
+}
+
+// TypeString is the type as GLSL writes it, arrays included
+func (d GLSLDeclaration) TypeString() string {
+	t := d.Type
+	if d.Block != "" {
+		var members []string
+		for _, m := range d.Members {
+			members = append(members, m.TypeString()+" "+m.Name)
+		}
+		t = d.Block + " { " + strings.Join(members, "; ") + " }"
+	}
+	for _, n := range d.Array {
+		t += "[" + n + "]"
+	}
+
+	return t
+}
+
+func (d GLSLDeclaration) has(qualifier string) bool {
+	for _, q := range d.Qualifiers {
+		if q == qualifier {
+			return true
+		}
+	}
+
+	return false
+}
+
+// key is what the next stage matches on, blocks go by block name
+func (d GLSLDeclaration) key() string {
+	if d.Block != "" {
+		return d.Block
+	}
+
+	return d.Name
+}
+
+// GLSLInterface is what a shader declares to the stages around it, read
+// without a compiler so it works where there's no GL context
+type GLSLInterface struct {
+	Stage string
+	// Version is the #version argument, "410" or "300 es", empty without one
+	Version  string
+	Inputs   []GLSLDeclaration
+	Outputs  []GLSLDeclaration
+	Uniforms []GLSLDeclaration
+	Buffers  []GLSLDeclaration
+
+	// identifier counts, declarations included
+	seen     map[string]int
+	declared map[string]int
+}
+
+// Referenced reports whether name shows up anywhere other than where it's
+// declared. It doesn't know about dead code or the preprocessor, a
+// uniform only used under #if 0 still counts.
+func (i *GLSLInterface) Referenced(name string) bool {
+	return i.seen[name] > i.declared[name]
+}
+
+// glslToken is an identifier, a number or a single punctuation character
+type glslToken struct {
+	text   string
+	line   int
+	column int
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+// stripComments blanks out comments, keeping newlines so lines still count
+func stripComments(source string) (string, error) {
+	b := []byte(source)
+	for i := 0; i < len(b); i++ {
+		if b[i] != '/' || i+1 >= len(b) {
+			continue
+		}
+		switch b[i+1] {
+		case '/':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		case '*':
+			end := strings.Index(string(b[i+2:]), "*/")
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated /* comment", ErrInvalidShader)
+			}
+			for j := i; j < i+2+end+2; j++ {
+				if b[j] != '\n' {
+					b[j] = ' '
+				}
+			}
+			i += end + 3
+		}
+	}
+
+	return string(b), nil
+}
+
+// tokenizeGLSL splits source into tokens, preprocessor lines are dropped
+// except for the first #version which is returned
+func tokenizeGLSL(source string) ([]glslToken, string, error) {
+	source, err := stripComments(strings.TrimRight(source, "\x00"))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var tokens []glslToken
+	version := ""
+	for n, line := range strings.Split(source, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			if v, ok := directive(line, "version"); ok && version == "" {
+				version = v
+			}
+			continue
+		}
+		for i := 0; i < len(line); {
+			c := line[i]
+			start := i
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				i++
+				continue
+			case isIdentStart(c):
+				for i < len(line) && isIdentChar(line[i]) {
+					i++
+				}
+			case c >= '0' && c <= '9' || c == '.' && i+1 < len(line) && line[i+1] >= '0' && line[i+1] <= '9':
+				// Good enough for 1.0e-3f and 0xFFu, the value isn't needed
+				for i < len(line) && (isIdentChar(line[i]) || line[i] == '.' ||
+					(line[i] == '-' || line[i] == '+') && (line[i-1] == 'e' || line[i-1] == 'E')) {
+					i++
+				}
+			default:
+				i++
+			}
+			tokens = append(tokens, glslToken{text: line[start:i], line: n + 1, column: start + 1})
+		}
+	}
+
+	return tokens, version, nil
+}
+
+var glslStorage = map[string]bool{"in": true, "out": true, "uniform": true, "buffer": true, "attribute": true, "varying": true}
+
+var glslQualifiers = map[string]bool{
+	"const": true, "flat": true, "smooth": true, "noperspective": true, "centroid": true, "sample": true,
+	"patch": true, "invariant": true, "precise": true, "highp": true, "mediump": true, "lowp": true,
+	"readonly": true, "writeonly": true, "coherent": true, "volatile": true, "restrict": true, "shared": true,
+}
+
+// ParseGLSLInterface reads the top level declarations of one stage. Only
+// #version is looked at among the directives, run the Preprocessor first
+// for anything that #includes or #ifdefs declarations in and out.
+func ParseGLSLInterface(stage, source string) (*GLSLInterface, error) {
+	tokens, version, err := tokenizeGLSL(source)
+	if err != nil {
+		return nil, err
+	}
+	iface := &GLSLInterface{Stage: stage, Version: version, seen: map[string]int{}, declared: map[string]int{}}
+	for _, t := range tokens {
+		if isIdentStart(t.text[0]) {
+			iface.seen[t.text]++
+		}
+	}
+
+	depth := 0
+	var stmt []glslToken
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.text == "{" && depth == 0 && isInterfaceBlock(stmt):
+			end, err := iface.parseBlock(stmt, tokens, i)
+			if err != nil {
+				return nil, err
+			}
+			stmt, i = nil, end
+		case t.text == "{":
+			depth++
+		case t.text == "}":
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("%w: line %d: unbalanced }", ErrInvalidShader, t.line)
+			}
+			// A function body ends without a ;
+			if depth == 0 {
+				stmt = nil
+			}
+		case depth > 0:
+		case t.text == ";":
+			for _, d := range parseGLSLDeclaration(stmt) {
+				iface.add(d)
+			}
+			stmt = nil
+		default:
+			stmt = append(stmt, t)
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced {", ErrInvalidShader)
+	}
+
+	return iface, nil
+}
+
+// isInterfaceBlock is "uniform Matrices" right before a {, as opposed to
+// a function or struct body
+func isInterfaceBlock(stmt []glslToken) bool {
+	if len(stmt) < 2 || !isIdentStart(stmt[len(stmt)-1].text[0]) {
+		return false
+	}
+	for _, t := range stmt {
+		if glslStorage[t.text] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseBlock reads an interface block whose { is tokens[open], returning
+// the index of the ; that ends it
+func (iface *GLSLInterface) parseBlock(head, tokens []glslToken, open int) (int, error) {
+	closing := -1
+	for i := open + 1; i < len(tokens); i++ {
+		if tokens[i].text == "}" {
+			closing = i
+			break
+		}
+	}
+	end := -1
+	for i := closing + 1; closing >= 0 && i < len(tokens); i++ {
+		if tokens[i].text == ";" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, fmt.Errorf("%w: line %d: unterminated interface block", ErrInvalidShader, tokens[open].line)
+	}
+
+	// The head parses like a declaration with the block name as its name
+	name := head[len(head)-1]
+	decls := parseGLSLDeclaration(append(append([]glslToken{}, head[:len(head)-1]...), glslToken{text: "block"}, name))
+	if len(decls) != 1 {
+		return 0, fmt.Errorf("%w: line %d: malformed interface block %s", ErrInvalidShader, name.line, name.text)
+	}
+	block := decls[0]
+	block.Type, block.Block, block.Name = "", name.text, ""
+	iface.declared[name.text]++
+
+	var member []glslToken
+	for _, t := range tokens[open+1 : closing] {
+		if t.text != ";" {
+			member = append(member, t)
+			continue
+		}
+		// Members take the block's storage
+		for _, m := range parseGLSLDeclaration(append([]glslToken{{text: block.Storage}}, member...)) {
+			block.Members = append(block.Members, m)
+			iface.declared[m.Name]++
+		}
+		member = nil
+	}
+
+	instance := tokens[closing+1 : end]
+	if len(instance) > 0 && isIdentStart(instance[0].text[0]) {
+		block.Name, block.Line, block.Column = instance[0].text, instance[0].line, instance[0].column
+		block.Array = parseArrays(instance[1:])
+	}
+	iface.add(block)
+
+	return end, nil
+}
+
+// add files d under its storage, mapping the pre 1.30 keywords
+func (iface *GLSLInterface) add(d GLSLDeclaration) {
+	if d.Name != "" {
+		iface.declared[d.Name]++
+	}
+
+	switch d.Storage {
+	case "attribute":
+		d.Storage = "in"
+	case "varying":
+		if iface.Stage == "vertex" {
+			d.Storage = "out"
+		} else {
+			d.Storage = "in"
+		}
+	}
+	switch d.Storage {
+	case "in":
+		iface.Inputs = append(iface.Inputs, d)
+	case "out":
+		iface.Outputs = append(iface.Outputs, d)
+	case "uniform":
+		iface.Uniforms = append(iface.Uniforms, d)
+	case "buffer":
+		iface.Buffers = append(iface.Buffers, d)
+	}
+}
+
+// parseGLSLDeclaration reads one statement, which declares nothing unless
+// it has a storage qualifier. "layout(triangles) in;" and precision
+// statements come out empty.
+func parseGLSLDeclaration(stmt []glslToken) []GLSLDeclaration {
+	base := GLSLDeclaration{Location: -1}
+	i := 0
+qualifiers:
+	for i < len(stmt) {
+		w := stmt[i].text
+		switch {
+		case w == "layout":
+			i = base.parseLayout(stmt, i+1)
+		case glslStorage[w]:
+			base.Storage = w
+			i++
+		case glslQualifiers[w]:
+			base.Qualifiers = append(base.Qualifiers, w)
+			i++
+		default:
+			break qualifiers
+		}
+	}
+	if base.Storage == "" || i >= len(stmt) || !isIdentStart(stmt[i].text[0]) {
+		return nil
+	}
+	base.Type = stmt[i].text
+	i++
+	typeArray, i := bracketed(stmt, i)
+
+	var decls []GLSLDeclaration
+	for i < len(stmt) {
+		if !isIdentStart(stmt[i].text[0]) {
+			break
+		}
+		d := base
+		d.Name, d.Line, d.Column = stmt[i].text, stmt[i].line, stmt[i].column
+		var arrays []glslToken
+		arrays, i = bracketed(stmt, i+1)
+		d.Array = append(parseArrays(arrays), parseArrays(typeArray)...)
+		decls = append(decls, d)
+
+		// Skip an initializer, uniforms can have one
+		for paren := 0; i < len(stmt); i++ {
+			switch stmt[i].text {
+			case "(", "[", "{":
+				paren++
+			case ")", "]", "}":
+				paren--
+			}
+			if stmt[i].text == "," && paren == 0 {
+				i++
+				break
+			}
+		}
+	}
+
+	return decls
+}
+
+// parseLayout reads "(location = 2, ...)" from stmt[i], returning the
+// index after the )
+func (d *GLSLDeclaration) parseLayout(stmt []glslToken, i int) int {
+	if i >= len(stmt) || stmt[i].text != "(" {
+		return i
+	}
+	for i++; i < len(stmt) && stmt[i].text != ")"; i++ {
+		if stmt[i].text == "location" && i+2 < len(stmt) && stmt[i+1].text == "=" {
+			fmt.Sscan(stmt[i+2].text, &d.Location)
+		}
+	}
+
+	return i + 1
+}
+
+// bracketed takes the run of [...] starting at stmt[i]
+func bracketed(stmt []glslToken, i int) ([]glslToken, int) {
+	start := i
+	for i < len(stmt) && stmt[i].text == "[" {
+		for i < len(stmt) && stmt[i].text != "]" {
+			i++
+		}
+		i++
+	}
+	if i > len(stmt) {
+		i = len(stmt)
+	}
+
+	return stmt[start:i], i
+}
+
+// parseArrays turns the tokens of "[3][]" into ["3", ""]
+func parseArrays(tokens []glslToken) []string {
+	var sizes []string
+	var size strings.Builder
+	for _, t := range tokens {
+		switch t.text {
+		case "[":
+			size.Reset()
+		case "]":
+			sizes = append(sizes, size.String())
+		default:
+			size.WriteString(t.text)
+		}
+	}
+
+	return sizes
+}
+
+// perVertex reports whether d is arrayed per vertex, tessellation and
+// geometry inputs and tessellation control outputs are, except patch ones
+func perVertex(stage string, d GLSLDeclaration) bool {
+	if d.has("patch") {
+		return false
+	}
+	if d.Storage == "in" {
+		return stage == "tessControl" || stage == "tessEvaluation" || stage == "geometry"
+	}
+
+	return stage == "tessControl"
+}
+
+// matchType is the type two stages have to agree on, without the array
+// level that only says how many vertices there are
+func matchType(stage string, d GLSLDeclaration) string {
+	if perVertex(stage, d) && len(d.Array) > 0 {
+		d.Array = d.Array[1:]
+	}
+
+	return d.TypeString()
+}
+
+// interfaceSource is one stage to check, File names it in diagnostics
+type interfaceSource struct {
+	stage  string
+	file   string
+	source string
+}
+
+// CheckShaderInterfaces parses the stages, keyed by the names
+// SetShaderSource uses, and reports what would fail to link or is likely
+// a mistake:
+//
+//   - an input with no output of the same name and type in the stage before
+//     it, e.g. a vColor typo, is an error
+//   - a uniform declared with different types in two stages is an error
+//   - an output nothing reads, a uniform that's never used and stages on
+//     different #versions are warnings
+//
+// p expands the sources first when it isn't nil, and the diagnostics then
+// point into the files that were included. No GL context is needed so
+// shader constants can be checked from plain go test.
+func CheckShaderInterfaces(p *Preprocessor, sources map[string]string) ([]Diagnostic, error) {
+	var stages []interfaceSource
+	for _, stage := range shaderStages {
+		if source := sources[stage.name]; source != "" {
+			stages = append(stages, interfaceSource{stage.name, stage.name, source})
+		}
+	}
+	for name := range sources {
+		found := false
+		for _, stage := range shaderStages {
+			found = found || stage.name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q, use one of %s", ErrUnsupportedShaderType, name, stageNames())
+		}
+	}
+
+	return checkShaderInterfaces(p, stages)
+}
+
+// CheckInterfaces runs CheckShaderInterfaces over the sources SetProgram
+// would link, through the manager's Preprocessor
+func (glm *GLManager) CheckInterfaces() ([]Diagnostic, error) {
+	var stages []interfaceSource
+	for _, stage := range shaderStages {
+		if source := *glm.stageSource(stage.name); source != "" {
+			stages = append(stages, interfaceSource{stage.name, glm.stageFile(stage.name), source})
+		}
+	}
+
+	return checkShaderInterfaces(glm.preprocessor(), stages)
+}
+
+func checkShaderInterfaces(p *Preprocessor, stages []interfaceSource) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	var ifaces []*GLSLInterface
+	var report []func(d GLSLDeclaration, severity Severity, format string, args ...interface{})
+
+	for _, stage := range stages {
+		source := stage.source
+		var processed *ProcessedSource
+		if p != nil {
+			var err error
+			if processed, err = p.Process(stage.file, source); err != nil {
+				return nil, err
+			}
+			source = processed.Code
+		}
+		iface, err := ParseGLSLInterface(stage.stage, source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stage.file, err)
+		}
+		ifaces = append(ifaces, iface)
+
+		stage := stage
+		lines := splitSourceLines(source)
+		report = append(report, func(d GLSLDeclaration, severity Severity, format string, args ...interface{}) {
+			diagnostic := Diagnostic{Stage: stage.stage, File: stage.file, Line: d.Line, Column: d.Column, Severity: severity, Message: fmt.Sprintf(format, args...)}
+			if d.Line >= 1 && d.Line <= len(lines) {
+				diagnostic.Text = lines[d.Line-1]
+			}
+			if processed != nil {
+				if origin := processed.Origin(d.Line); origin.File >= 0 {
+					diagnostic.File = processed.Files[origin.File]
+					diagnostic.Line = origin.Line
+				}
+			}
+			diagnostics = append(diagnostics, diagnostic)
+		})
+	}
+
+	uniforms := map[string]GLSLDeclaration{}
+	uniformStage := map[string]string{}
+	for i, iface := range ifaces {
+		if i > 0 && iface.Version != ifaces[0].Version && iface.Version != "" && ifaces[0].Version != "" {
+			report[i](GLSLDeclaration{Line: versionLine(stages[i], p)}, SeverityWarning,
+				"#version %s doesn't match the %s shader's #version %s", iface.Version, ifaces[0].Stage, ifaces[0].Version)
+		}
+
+		for _, u := range iface.Uniforms {
+			if !uniformUsed(iface, u) {
+				report[i](u, SeverityWarning, "uniform %s is declared but never used", u.key())
+			}
+			if other, ok := uniforms[u.key()]; ok && other.TypeString() != u.TypeString() {
+				report[i](u, SeverityError, "uniform %s is %s here but %s in the %s shader", u.key(), u.TypeString(), other.TypeString(), uniformStage[u.key()])
+			} else if !ok {
+				uniforms[u.key()] = u
+				uniformStage[u.key()] = iface.Stage
+			}
+		}
+
+		if i == 0 || iface.Stage == "compute" {
+			continue
+		}
+		prev := ifaces[i-1]
+		read := map[int]bool{}
+		for _, in := range iface.Inputs {
+			if strings.HasPrefix(in.key(), "gl_") {
+				continue
+			}
+			j := matchOutput(prev, in)
+			if j < 0 {
+				report[i](in, SeverityError, "input %s has no matching output in the %s shader", in.key(), prev.Stage)
+				continue
+			}
+			read[j] = true
+			out := prev.Outputs[j]
+			if matchType(prev.Stage, out) != matchType(iface.Stage, in) {
+				report[i](in, SeverityError, "input %s is %s but the %s shader outputs %s", in.key(), matchType(iface.Stage, in), prev.Stage, matchType(prev.Stage, out))
+			}
+		}
+		for j, out := range prev.Outputs {
+			if !read[j] && !strings.HasPrefix(out.key(), "gl_") {
+				report[i-1](out, SeverityWarning, "output %s is never read by the %s shader", out.key(), iface.Stage)
+			}
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// matchOutput finds what feeds in, by name or by location when both
+// sides have one
+func matchOutput(prev *GLSLInterface, in GLSLDeclaration) int {
+	for j, out := range prev.Outputs {
+		if out.key() == in.key() {
+			return j
+		}
+	}
+	if in.Location < 0 {
+		return -1
+	}
+	for j, out := range prev.Outputs {
+		if out.Location == in.Location {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// uniformUsed looks for the instance name of a block, or any member of a
+// block without one
+func uniformUsed(iface *GLSLInterface, u GLSLDeclaration) bool {
+	if u.Block == "" || u.Name != "" {
+		return iface.Referenced(u.Name)
+	}
+	for _, m := range u.Members {
+		if iface.Referenced(m.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionLine is the line #version is on in what was parsed
+func versionLine(stage interfaceSource, p *Preprocessor) int {
+	if p != nil {
+		// The Preprocessor always puts it first
+		return 1
+	}
+	for i, line := range splitSourceLines(stage.source) {
+		if _, ok := directive(line, "version"); ok {
+			return i + 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,151 @@
+package graphicsManager
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGLSLInterface(t *testing.T) {
+	iface, err := ParseGLSLInterface("vertex", `#version 410
+/* a block comment with
+   uniform float uHidden; in it */
+layout(location = 1) in vec4 aColor;
+in vec3 aPosition, aNormal; // in vec2 aUV;
+flat out int vId;
+out vec4 vColor[2];
+uniform mat4 uMVP = mat4(1.0, 0.0, 0.0, 0.0,
+	0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0, 0.0, 0.0, 0.0, 0.0, 1.0);
+uniform float uWeights[4];
+layout(std140) uniform Lights {
+	vec3 position;
+	vec3 colors[3];
+} lights;
+struct Material { vec4 tint; };
+precision highp float;
+
+float scale(float x) { return x * uWeights[0]; }
+
+void main() {
+	vColor[0] = aColor;
+	gl_Position = uMVP * vec4(aPosition, 1.0);
+}
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "410", iface.Version)
+
+	if assert.Len(t, iface.Inputs, 3) {
+		assert.Equal(t, GLSLDeclaration{Storage: "in", Type: "vec4", Name: "aColor", Location: 1, Line: 4, Column: 30}, iface.Inputs[0])
+		assert.Equal(t, "aNormal", iface.Inputs[2].Name)
+	}
+	if assert.Len(t, iface.Outputs, 2) {
+		assert.Equal(t, []string{"flat"}, iface.Outputs[0].Qualifiers)
+		assert.Equal(t, "vec4[2]", iface.Outputs[1].TypeString())
+	}
+	if assert.Len(t, iface.Uniforms, 3) {
+		assert.Equal(t, "uMVP", iface.Uniforms[0].Name)
+		assert.Equal(t, "float[4]", iface.Uniforms[1].TypeString())
+		assert.Equal(t, "Lights { vec3 position; vec3[3] colors }", iface.Uniforms[2].TypeString())
+		assert.Equal(t, "lights", iface.Uniforms[2].Name)
+	}
+
+	assert.True(t, iface.Referenced("uMVP"))
+	assert.True(t, iface.Referenced("uWeights"))
+	assert.False(t, iface.Referenced("lights"))
+	assert.False(t, iface.Referenced("aNormal"))
+
+	// Older GLSL spells them attribute and varying
+	old, err := ParseGLSLInterface("fragment", "varying vec4 vColor;\nvoid main() { gl_FragColor = vColor; }\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "in", old.Inputs[0].Storage)
+
+	_, err = ParseGLSLInterface("vertex", "void main() {\n")
+	assert.ErrorIs(t, err, ErrInvalidShader)
+	_, err = ParseGLSLInterface("vertex", "/* never closed\n")
+	assert.ErrorIs(t, err, ErrInvalidShader)
+}
+
+func messages(diagnostics []Diagnostic) []string {
+	var out []string
+	for _, d := range diagnostics {
+		out = append(out, d.Severity.String()+": "+d.Stage+": "+d.Message)
+	}
+
+	return out
+}
+
+func TestCheckShaderInterfaces(t *testing.T) {
+	vs := `#version 410
+in vec4 aPosition;
+out vec4 vColour;
+out vec3 vNormal;
+out vec2 vUV;
+uniform mat4 uMVP;
+uniform float uTime;
+uniform vec4 uTint;
+void main() { vColour = vec4(1.0); vNormal = vec3(0.0); gl_Position = uMVP * aPosition; }
+`
+	fs := `#version 410
+in vec4 vColor;
+in vec4 vNormal;
+layout(location = 7) in vec2 texCoord;
+uniform vec3 uTint;
+out vec4 fColor;
+void main() { fColor = vColor * vec4(vNormal.xyz, 1.0) * vec4(uTint, 1.0); }
+`
+	diagnostics, err := CheckShaderInterfaces(nil, map[string]string{"vertex": vs, "fragment": fs})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"warning: vertex: uniform uTime is declared but never used",
+		"warning: vertex: uniform uTint is declared but never used",
+		"error: fragment: uniform uTint is vec3 here but vec4 in the vertex shader",
+		"error: fragment: input vColor has no matching output in the vertex shader",
+		"error: fragment: input vNormal is vec4 but the vertex shader outputs vec3",
+		"error: fragment: input texCoord has no matching output in the vertex shader",
+		"warning: vertex: output vColour is never read by the fragment shader",
+		"warning: vertex: output vUV is never read by the fragment shader",
+	}, messages(diagnostics))
+	assert.Equal(t, "fragment:2:9: error: input vColor has no matching output in the vertex shader\n\tin vec4 vColor;\n\t        ^", diagnostics[3].Format())
+
+	// Locations match too, whatever the names
+	diagnostics, err = CheckShaderInterfaces(nil, map[string]string{
+		"vertex":   "#version 410\nlayout(location = 0) out vec2 uv;\nvoid main() { uv = vec2(0.0); }\n",
+		"fragment": "#version 330\nlayout(location = 0) in vec2 texCoord;\nout vec4 c;\nvoid main() { c = vec4(texCoord, 0.0, 1.0); }\n",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"warning: fragment: #version 330 doesn't match the vertex shader's #version 410"}, messages(diagnostics))
+
+	_, err = CheckShaderInterfaces(nil, map[string]string{"mesh": "void main() {}"})
+	assert.ErrorIs(t, err, ErrUnsupportedShaderType)
+}
+
+func TestCheckShaderInterfaces_Tessellation(t *testing.T) {
+	diagnostics, err := CheckShaderInterfaces(nil, map[string]string{
+		"vertex":         "out vec3 vPos;\nvoid main() { vPos = vec3(0.0); }\n",
+		"tessControl":    "layout(vertices = 3) out;\nin vec3 vPos[];\nout vec3 tcPos[];\npatch out float level;\nvoid main() { tcPos[gl_InvocationID] = vPos[gl_InvocationID]; level = 1.0; }\n",
+		"tessEvaluation": "layout(triangles) in;\nin vec3 tcPos[];\npatch in float level;\nvoid main() { gl_Position = vec4(tcPos[0] * level, 1.0); }\n",
+		"geometry":       "layout(triangles) in;\nlayout(triangle_strip, max_vertices = 3) out;\nout VertexData { vec3 normal; } gOut;\nvoid main() { gOut.normal = vec3(0.0); EmitVertex(); }\n",
+		"fragment":       "in VertexData { vec3 normal; } fIn;\nout vec4 c;\nvoid main() { c = vec4(fIn.normal, 1.0); }\n",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, messages(diagnostics))
+}
+
+func TestGLManager_CheckInterfaces(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common.glsl": {Data: []byte("uniform float uUnused;\nout vec4 vColor;\n")},
+		"cube.vert":   {Data: []byte("#version 410\n#include \"common.glsl\"\nvoid main() { vColor = vec4(1.0); }\n")},
+		"cube.frag":   {Data: []byte("#version 410\nin vec4 vColor;\nout vec4 fColor;\nvoid main() { fColor = vColor; }\n")},
+	}
+	manager := GLManager{Preprocessor: NewPreprocessor(fsys)}
+	assert.NoError(t, manager.LoadShaders(fsys, "cube.vert", "cube.frag"))
+
+	diagnostics, err := manager.CheckInterfaces()
+	assert.NoError(t, err)
+	if assert.Len(t, diagnostics, 1) {
+		assert.Equal(t, "common.glsl", diagnostics[0].File)
+		assert.Equal(t, 1, diagnostics[0].Line)
+		assert.Equal(t, "uniform uUnused is declared but never used", diagnostics[0].Message)
+	}
+}
@@ -0,0 +1,158 @@
+// Package golden compares rendered scenes against reference PNGs kept in
+// the testdata directory of the package under test. Run the tests with
+// -update to write the current output as the new reference.
+package golden
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
+)
+
+var update = flag.Bool("update", false, "rewrite golden images in testdata/")
+
+// DefaultTolerance absorbs rounding differences between float paths
+const DefaultTolerance = 2
+
+// Options controls how strict a comparison is
+type Options struct {
+	// Tolerance is the largest per channel difference still counted as equal
+	Tolerance uint8
+	// MaxMismatched is how many pixels may exceed Tolerance before failing
+	MaxMismatched int
+}
+
+// Scene draws into a freshly cleared rasterizer
+type Scene func(r *graphicsManager.Rasterizer)
+
+// Render runs a scene on a new width x height rasterizer
+func Render(width, height int, scene Scene) *image.RGBA {
+	r := graphicsManager.NewRasterizer(width, height)
+	scene(r)
+
+	return r.Image()
+}
+
+// AssertScene renders a scene and compares it with testdata/<name>.png
+func AssertScene(t testing.TB, name string, width, height int, scene Scene, opts Options) {
+	t.Helper()
+	AssertImage(t, name, Render(width, height, scene), opts)
+}
+
+// AssertImage compares got with testdata/<name>.png. On failure the actual
+// image and a diff highlighting the offending pixels in red are written
+// next to the golden so they can be inspected or uploaded by CI.
+func AssertImage(t testing.TB, name string, got image.Image, opts Options) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		if err := WritePNG(path, got); err != nil {
+			t.Fatalf("golden: updating %s: %v", path, err)
+		}
+		t.Logf("golden: wrote %s", path)
+		return
+	}
+
+	want, err := ReadPNG(path)
+	if err != nil {
+		t.Fatalf("golden: %v (run with -update to create it)", err)
+	}
+
+	diff, mismatched, err := Compare(want, got, opts.Tolerance)
+	if err != nil {
+		t.Fatalf("golden: %s: %v", path, err)
+	}
+	if mismatched <= opts.MaxMismatched {
+		return
+	}
+
+	gotPath := filepath.Join("testdata", name+".got.png")
+	diffPath := filepath.Join("testdata", name+".diff.png")
+	if err := WritePNG(gotPath, got); err != nil {
+		t.Logf("golden: writing %s: %v", gotPath, err)
+	}
+	if err := WritePNG(diffPath, diff); err != nil {
+		t.Logf("golden: writing %s: %v", diffPath, err)
+	}
+	t.Errorf("golden: %s: %d pixels differ by more than %d, see %s", path, mismatched, opts.Tolerance, diffPath)
+}
+
+// Compare counts the pixels of got that differ from want by more than
+// tolerance in any channel. The returned diff shows matching pixels as a
+// faded copy of want and mismatches in solid red.
+func Compare(want, got image.Image, tolerance uint8) (*image.RGBA, int, error) {
+	if want.Bounds().Size() != got.Bounds().Size() {
+		return nil, 0, fmt.Errorf("size mismatch: want %v, got %v", want.Bounds().Size(), got.Bounds().Size())
+	}
+
+	w := toRGBA(want)
+	g := toRGBA(got)
+	bounds := w.Bounds()
+	diff := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	mismatched := 0
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			wc := w.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			gc := g.RGBAAt(g.Bounds().Min.X+x, g.Bounds().Min.Y+y)
+
+			if channelDiff(wc.R, gc.R) > tolerance || channelDiff(wc.G, gc.G) > tolerance ||
+				channelDiff(wc.B, gc.B) > tolerance || channelDiff(wc.A, gc.A) > tolerance {
+				mismatched++
+				diff.SetRGBA(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+
+			gray := uint8((uint32(wc.R) + uint32(wc.G) + uint32(wc.B)) / 3)
+			faded := 192 + gray/4
+			diff.SetRGBA(x, y, color.RGBA{faded, faded, faded, 255})
+		}
+	}
+
+	return diff, mismatched, nil
+}
+
+func ReadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+func WritePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return graphicsManager.SavePNG(path, img)
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return rgba
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+
+	return b - a
+}
@@ -0,0 +1,57 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func solid(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	want := solid(4, 4, color.RGBA{100, 100, 100, 255})
+	got := solid(4, 4, color.RGBA{102, 99, 100, 255})
+
+	_, mismatched, err := Compare(want, got, 2)
+	assert.NoError(t, err)
+	assert.Zero(t, mismatched)
+
+	got.SetRGBA(1, 2, color.RGBA{0, 0, 0, 255})
+	diff, mismatched, err := Compare(want, got, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, mismatched)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, diff.RGBAAt(1, 2))
+	assert.NotEqual(t, color.RGBA{255, 0, 0, 255}, diff.RGBAAt(0, 0))
+
+	_, _, err = Compare(want, solid(2, 2, color.RGBA{}), 2)
+	assert.Error(t, err)
+}
+
+func TestPNGRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "img.png")
+	img := solid(3, 2, color.RGBA{10, 20, 30, 255})
+
+	assert.NoError(t, WritePNG(path, img))
+	back, err := ReadPNG(path)
+	assert.NoError(t, err)
+
+	_, mismatched, err := Compare(img, back, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, mismatched)
+
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+}
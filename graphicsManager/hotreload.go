@@ -0,0 +1,226 @@
+package graphicsManager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// ShaderPollInterval is how often RunLoop checks watched shader files
+var ShaderPollInterval = 250 * time.Millisecond
+
+// shaderWatch remembers which file each loaded stage came from and what
+// every watched file looked like the last time it was read. Files are
+// compared by content since mtimes are too coarse on some filesystems and
+// fstest.MapFS doesn't bother with them.
+type shaderWatch struct {
+	fsys fs.FS
+	// paths is the file behind each stage, by the name SetShaderSource uses
+	paths map[string]string
+
+	contents map[watchedFile][]byte
+	lastPoll time.Time
+}
+
+// watchedFile is a shader or one of its includes, included files are read
+// through preprocessor's FS, fsys first
+type watchedFile struct {
+	include bool
+	name    string
+}
+
+// LoadShaders reads VS and FS from fsys and keeps watching both, and
+// everything they #include, for RunLoop to reload. Includes are looked up
+// in fsys before the Preprocessor's FS. Call SetProgram after it like with
+// sources set by hand.
+func (glm *GLManager) LoadShaders(fsys fs.FS, vertexPath, fragmentPath string) error {
+	return glm.LoadShaderStages(fsys, map[string]string{"vertex": vertexPath, "fragment": fragmentPath})
+}
+
+// LoadShaderStages is LoadShaders for any set of stages, keyed by the
+// names SetShaderSource takes, e.g. a geometry or tessellation shader next
+// to the vertex and fragment ones. Stages not in paths keep whatever
+// source they have and aren't watched.
+func (glm *GLManager) LoadShaderStages(fsys fs.FS, paths map[string]string) error {
+	watched := make(map[string]string, len(paths))
+	for name, path := range paths {
+		if glm.stageSource(name) == nil {
+			return fmt.Errorf("%w: %q, please declare one of %s", ErrUnsupportedShaderType, name, stageNames())
+		}
+		watched[name] = path
+	}
+	watch := &shaderWatch{fsys: fsys, paths: watched, contents: map[watchedFile][]byte{}}
+	sources, err := glm.readShaders(watch)
+	if err != nil {
+		return err
+	}
+	glm.setStageSources(sources)
+	glm.watch = watch
+
+	return nil
+}
+
+// readShaders reads every watched stage and remembers their contents, the
+// sources are left for the caller to set
+func (glm *GLManager) readShaders(watch *shaderWatch) (map[string]string, error) {
+	sources := map[string]string{}
+	for name, path := range watch.paths {
+		data, err := fs.ReadFile(watch.fsys, path)
+		if err != nil {
+			return nil, err
+		}
+		watch.contents[watchedFile{name: path}] = data
+		sources[name] = string(data)
+	}
+
+	return sources, nil
+}
+
+// setStageSources sets the named stages and returns what they held before
+func (glm *GLManager) setStageSources(sources map[string]string) map[string]string {
+	old := make(map[string]string, len(sources))
+	for name, source := range sources {
+		field := glm.stageSource(name)
+		old[name] = *field
+		*field = source
+	}
+
+	return old
+}
+
+// overlayFS opens files from top and falls back to base for the ones top
+// doesn't have
+type overlayFS struct {
+	top, base fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if err == nil || o.base == nil || !errors.Is(err, fs.ErrNotExist) {
+		return f, err
+	}
+
+	return o.base.Open(name)
+}
+
+// preprocessor is the Preprocessor the shaders are built with. Once
+// LoadShaders is watching a directory includes are looked up there before
+// the Preprocessor's own FS, so an edited copy of a library file like
+// quaternion.glsl next to the shaders wins over the embedded one.
+func (glm *GLManager) preprocessor() *Preprocessor {
+	if glm.watch == nil || glm.Preprocessor == nil {
+		return glm.Preprocessor
+	}
+	p := *glm.Preprocessor
+	p.FS = overlayFS{top: glm.watch.fsys, base: p.FS}
+
+	return &p
+}
+
+// watchedFiles is every loaded stage plus whatever the last build included
+func (glm *GLManager) watchedFiles() []watchedFile {
+	var files []watchedFile
+	for _, stage := range shaderStages {
+		if path, ok := glm.watch.paths[stage.name]; ok {
+			files = append(files, watchedFile{name: path})
+		}
+	}
+	if glm.Preprocessor == nil {
+		return files
+	}
+	seen := map[string]bool{}
+	for _, stage := range shaderStages {
+		if p := glm.processed[stage.name]; p != nil {
+			for _, name := range p.Files[1:] {
+				if !seen[name] {
+					seen[name] = true
+					files = append(files, watchedFile{include: true, name: name})
+				}
+			}
+		}
+	}
+
+	return files
+}
+
+// ShadersChanged reports whether a watched file differs from what was
+// last read. Includes seen for the first time are only recorded.
+func (glm *GLManager) ShadersChanged() bool {
+	if glm.watch == nil {
+		return false
+	}
+
+	changed := false
+	for _, f := range glm.watchedFiles() {
+		fsys := glm.watch.fsys
+		if f.include {
+			fsys = glm.preprocessor().FS
+		}
+		data, err := fs.ReadFile(fsys, f.name)
+		if err != nil {
+			// Editors often delete and recreate, try again next poll
+			continue
+		}
+		old, ok := glm.watch.contents[f]
+		if ok && bytes.Equal(old, data) {
+			continue
+		}
+		changed = changed || ok
+		glm.watch.contents[f] = data
+	}
+
+	return changed
+}
+
+// ReloadShaders reads the watched files again and relinks. A shader that
+// doesn't build leaves the running program and the stage sources alone and
+// the log is printed, so a typo while editing doesn't kill the window. The new
+// program starts
+// with default uniform values, anything not set per frame has to be set
+// again.
+func (glm *GLManager) ReloadShaders() error {
+	if glm.watch == nil {
+		return nil
+	}
+	sources, err := glm.readShaders(glm.watch)
+	if err != nil {
+		fmt.Println("Shader reload failed:", err)
+		return err
+	}
+	// SetProgram builds from the stage fields, they only keep the new
+	// sources if it works
+	old := glm.setStageSources(sources)
+	if err := glm.SetProgram(); err != nil {
+		glm.setStageSources(old)
+		fmt.Printf("Shader reload failed, keeping program %d:\n%s\n", glm.Program, FormatShaderError(err))
+		return err
+	}
+	if err := glm.BindProgram(); err != nil {
+		return err
+	}
+	// Attribute locations can move between links
+	if glm.layoutBound {
+		if err := glm.BindLayout(); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Shaders reloaded, program", glm.Program)
+
+	return nil
+}
+
+// PollShaders is what RunLoop calls every frame, it only looks at the
+// files every ShaderPollInterval
+func (glm *GLManager) PollShaders() bool {
+	if glm.watch == nil || time.Since(glm.watch.lastPoll) < ShaderPollInterval {
+		return false
+	}
+	glm.watch.lastPoll = time.Now()
+	if !glm.ShadersChanged() {
+		return false
+	}
+
+	return glm.ReloadShaders() == nil
+}
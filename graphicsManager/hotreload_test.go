@@ -0,0 +1,163 @@
+package graphicsManager
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func shaderFS() fstest.MapFS {
+	return fstest.MapFS{
+		"cube.vert":   {Data: []byte("#include \"common.glsl\"\nvoid main() {}\n")},
+		"cube.frag":   {Data: []byte("void main() {}\n")},
+		"common.glsl": {Data: []byte("const float SCALE = 1.0;\n")},
+	}
+}
+
+func TestGLManager_ReloadShaders(t *testing.T) {
+	fsys := shaderFS()
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec, Preprocessor: NewPreprocessor(fsys)}
+
+	assert.Error(t, manager.LoadShaders(fsys, "cube.vert", "missing.frag"))
+	assert.NoError(t, manager.LoadShaders(fsys, "cube.vert", "cube.frag"))
+	assert.Equal(t, "void main() {}\n", manager.FS)
+	assert.NoError(t, manager.SetProgram())
+	shader := manager.Shader()
+	first := manager.Program
+
+	// The first look only records the include
+	assert.False(t, manager.ShadersChanged())
+	assert.False(t, manager.ShadersChanged())
+
+	fsys["common.glsl"] = &fstest.MapFile{Data: []byte("const float SCALE = 2.0;\n")}
+	assert.True(t, manager.ShadersChanged())
+	assert.False(t, manager.ShadersChanged())
+	assert.NoError(t, manager.ReloadShaders())
+	assert.NotEqual(t, first, manager.Program)
+	assert.Equal(t, manager.Program, rec.CurrentProgram())
+	// Anyone holding the ShaderProgram sees the new program
+	assert.Same(t, shader, manager.Shader())
+	assert.Equal(t, manager.Program, shader.ID)
+	assert.Contains(t, rec.CallsNamed("DeleteProgram")[0].Args, first)
+}
+
+func TestGLManager_ReloadKeepsProgramOnFailure(t *testing.T) {
+	fsys := shaderFS()
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec}
+	assert.NoError(t, manager.LoadShaders(fsys, "cube.vert", "cube.frag"))
+	assert.NoError(t, manager.SetProgram())
+	program := manager.Program
+
+	rec.FailShaderCompile = true
+	rec.InfoLog = "0:1(1): error: syntax error"
+	fsys["cube.frag"] = &fstest.MapFile{Data: []byte("void main() {\n")}
+	assert.True(t, manager.ShadersChanged())
+	err := manager.ReloadShaders()
+	var compileErr *ShaderCompileError
+	assert.ErrorAs(t, err, &compileErr)
+	assert.Equal(t, program, manager.Program)
+	assert.Equal(t, program, manager.Shader().ID)
+	assert.Zero(t, rec.Count("DeleteProgram"))
+	// The sources still match the running program
+	assert.Equal(t, "void main() {}\n", manager.FS)
+
+	// Fixing the file brings the reload back
+	rec.FailShaderCompile = false
+	fsys["cube.frag"] = &fstest.MapFile{Data: []byte("void main() {}\n")}
+	assert.True(t, manager.ShadersChanged())
+	assert.NoError(t, manager.ReloadShaders())
+	assert.NotEqual(t, program, manager.Program)
+}
+
+func TestGLManager_ReloadShadersIncludesFromWatchedDir(t *testing.T) {
+	// The library has both includes, the watched directory its own copy of one
+	library := fstest.MapFS{
+		"common.glsl": {Data: []byte("const float SCALE = 1.0;\n")},
+		"other.glsl":  {Data: []byte("const float OTHER = 1.0;\n")},
+	}
+	fsys := shaderFS()
+	fsys["cube.frag"] = &fstest.MapFile{Data: []byte("#include \"other.glsl\"\nvoid main() {}\n")}
+	fsys["common.glsl"] = &fstest.MapFile{Data: []byte("const float SCALE = 2.0;\n")}
+	manager := GLManager{Backend: &RecordingBackend{}, Preprocessor: NewPreprocessor(library)}
+	assert.NoError(t, manager.LoadShaders(fsys, "cube.vert", "cube.frag"))
+	assert.NoError(t, manager.SetProgram())
+	assert.Contains(t, manager.processed["vertex"].Code, "SCALE = 2.0")
+	assert.Contains(t, manager.processed["fragment"].Code, "OTHER = 1.0")
+	assert.False(t, manager.ShadersChanged())
+
+	// Editing the copy on disk is seen and built
+	fsys["common.glsl"] = &fstest.MapFile{Data: []byte("const float SCALE = 3.0;\n")}
+	assert.True(t, manager.ShadersChanged())
+	assert.NoError(t, manager.ReloadShaders())
+	assert.Contains(t, manager.processed["vertex"].Code, "SCALE = 3.0")
+
+	// The Preprocessor itself still only has the library
+	assert.Equal(t, fs.FS(library), manager.Preprocessor.FS)
+}
+
+func TestGLManager_LoadShaderStages(t *testing.T) {
+	fsys := shaderFS()
+	fsys["cube.geom"] = &fstest.MapFile{Data: []byte("#include \"common.glsl\"\nvoid main() {}\n")}
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec, Preprocessor: NewPreprocessor(fsys)}
+
+	assert.ErrorIs(t, manager.LoadShaderStages(fsys, map[string]string{"pixel": "cube.frag"}), ErrUnsupportedShaderType)
+	assert.NoError(t, manager.LoadShaderStages(fsys, map[string]string{
+		"vertex": "cube.vert", "geometry": "cube.geom", "fragment": "cube.frag",
+	}))
+	assert.Contains(t, manager.GS, "void main()")
+	assert.Equal(t, "cube.geom", manager.stageFile("geometry"))
+	assert.Equal(t, "tessControl", manager.stageFile("tessControl"))
+	assert.NoError(t, manager.SetProgram())
+	assert.Equal(t, []string{"cube.geom", "common.glsl"}, manager.processed["geometry"].Files)
+	assert.False(t, manager.ShadersChanged())
+
+	// The geometry file is watched like the other two
+	fsys["cube.geom"] = &fstest.MapFile{Data: []byte("void main() { EmitVertex(); }\n")}
+	assert.True(t, manager.ShadersChanged())
+	assert.NoError(t, manager.ReloadShaders())
+	assert.Equal(t, "void main() { EmitVertex(); }\n", manager.GS)
+
+	// A broken geometry shader leaves every stage as it was
+	rec.FailShaderCompile = true
+	fsys["cube.geom"] = &fstest.MapFile{Data: []byte("void main() {\n")}
+	fsys["cube.frag"] = &fstest.MapFile{Data: []byte("void main() { }\n")}
+	assert.True(t, manager.ShadersChanged())
+	assert.Error(t, manager.ReloadShaders())
+	assert.Equal(t, "void main() { EmitVertex(); }\n", manager.GS)
+	assert.Equal(t, "void main() {}\n", manager.FS)
+}
+
+func TestGLManager_RunLoopPollsShaders(t *testing.T) {
+	interval := ShaderPollInterval
+	ShaderPollInterval = 0
+	defer func() { ShaderPollInterval = interval }()
+
+	fsys := shaderFS()
+	rec := &RecordingBackend{}
+	ctx := &fakeContext{closeAfter: 4}
+	manager := GLManager{Context: ctx, Backend: rec}
+	assert.NoError(t, manager.LoadShaders(fsys, "cube.vert", "cube.frag"))
+	assert.NoError(t, manager.SetProgram())
+	program := manager.Program
+
+	frames := 0
+	manager.RenderCall = func() {
+		frames++
+		if frames == 2 {
+			fsys["cube.vert"] = &fstest.MapFile{Data: []byte("void main() { }\n")}
+		}
+	}
+	manager.RunLoop(1000)
+
+	assert.Equal(t, 4, frames)
+	assert.NotEqual(t, program, manager.Program)
+	assert.Equal(t, "void main() { }\n", manager.VS)
+
+	// Nothing is watched without LoadShaders
+	assert.False(t, (&GLManager{Backend: rec}).PollShaders())
+}
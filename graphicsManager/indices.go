@@ -0,0 +1,125 @@
+package graphicsManager
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// PrimitiveRestart in an index slice ends the current strip or fan, it is
+// translated to 0xFFFF when the indices are uploaded as uint16
+const PrimitiveRestart = ^uint32(0)
+
+// SetIndices keeps a copy of the index list and picks the smallest index
+// type that can hold it, uint16 unless an index reaches 0xFFFF
+func (glm *GLManager) SetIndices(indices []uint32) {
+	glm.indices = append([]uint32(nil), indices...)
+	glm.indexType = gl.UNSIGNED_SHORT
+	for _, index := range indices {
+		if index != PrimitiveRestart && index >= 0xFFFF {
+			glm.indexType = gl.UNSIGNED_INT
+			break
+		}
+	}
+}
+
+// SetIndexType forces gl.UNSIGNED_SHORT or gl.UNSIGNED_INT for the upload
+func (glm *GLManager) SetIndexType(xtype uint32) error {
+	switch xtype {
+	case gl.UNSIGNED_INT:
+	case gl.UNSIGNED_SHORT:
+		for _, index := range glm.indices {
+			if index != PrimitiveRestart && index >= 0xFFFF {
+				return fmt.Errorf("%w: index %d doesn't fit in uint16", ErrInvalidIndexType, index)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidIndexType, GLEnumName(xtype))
+	}
+	glm.indexType = xtype
+
+	return nil
+}
+
+func (glm *GLManager) Indices() []uint32 {
+	return glm.indices
+}
+
+// IndexType is gl.UNSIGNED_SHORT or gl.UNSIGNED_INT once indices are set
+func (glm *GLManager) IndexType() uint32 {
+	return glm.indexType
+}
+
+func (glm *GLManager) EBO() uint32 {
+	return glm.ebo
+}
+
+// SetPrimitiveRestart turns PrimitiveRestart markers on or off for DrawElements
+func (glm *GLManager) SetPrimitiveRestart(enabled bool) {
+	glm.primitiveRestart = enabled
+}
+
+// BindEBO uploads the indices into an element buffer attached to the first
+// VAO, so BindVAOs has to have run. Like BindVBOs the buffer is made once
+// and refilled on later calls.
+func (glm *GLManager) BindEBO() error {
+	if len(glm.vaos) == 0 {
+		return ErrNoVertexArray
+	}
+	b := glm.backend()
+
+	if glm.ebo == 0 {
+		glm.ebo = b.GenBuffer()
+	}
+
+	// The element array binding is VAO state, so the VAO stays bound until
+	// the buffer is attached and filled
+	b.BindVertexArray(glm.vaos[0])
+	b.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, glm.ebo)
+	if glm.indexType == gl.UNSIGNED_SHORT {
+		shorts := make([]uint16, len(glm.indices))
+		for i, index := range glm.indices {
+			shorts[i] = uint16(index)
+		}
+		if len(shorts) > 0 {
+			b.BufferData(gl.ELEMENT_ARRAY_BUFFER, 2*len(shorts), gl.Ptr(shorts), gl.STATIC_DRAW)
+		}
+	} else if len(glm.indices) > 0 {
+		b.BufferData(gl.ELEMENT_ARRAY_BUFFER, 4*len(glm.indices), gl.Ptr(glm.indices), gl.STATIC_DRAW)
+	}
+	b.BindVertexArray(0)
+
+	return nil
+}
+
+// DrawElements draws every index with the first VAO bound
+func (glm *GLManager) DrawElements(mode uint32) {
+	if len(glm.indices) == 0 {
+		return
+	}
+	b := glm.backend()
+
+	if glm.primitiveRestart {
+		b.Enable(gl.PRIMITIVE_RESTART)
+		if glm.indexType == gl.UNSIGNED_SHORT {
+			b.PrimitiveRestartIndex(0xFFFF)
+		} else {
+			b.PrimitiveRestartIndex(PrimitiveRestart)
+		}
+	} else {
+		b.Disable(gl.PRIMITIVE_RESTART)
+	}
+
+	if len(glm.vaos) > 0 {
+		b.BindVertexArray(glm.vaos[0])
+	}
+	b.DrawElements(mode, int32(len(glm.indices)), glm.indexType, 0)
+}
+
+// ReleaseEBO deletes the element buffer, the indices themselves are kept
+func (glm *GLManager) ReleaseEBO() {
+	if glm.ebo != 0 {
+		glm.backend().DeleteBuffer(glm.ebo)
+		glm.ebo = 0
+	}
+}
@@ -0,0 +1,119 @@
+package graphicsManager
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// indexedQuad sets up a manager with the four corners of clip space shared
+// between every draw, only the index list changes
+func indexedQuad(sb *SoftwareBackend) *GLManager {
+	manager := &GLManager{Backend: sb}
+	manager.SetGeoVertices([]mgl32.Vec4{{-1, -1, 0, 1}, {1, -1, 0, 1}, {1, 1, 0, 1}, {-1, 1, 0, 1}})
+	manager.SetColorVertices([]mgl32.Vec4{red, red, red, red})
+	manager.BindVBOs()
+	manager.BindVAOs()
+
+	sb.BindVertexArray(manager.VAOs()[0])
+	for loc, vbo := range manager.VBOs() {
+		sb.BindBuffer(gl.ARRAY_BUFFER, vbo)
+		sb.VertexAttribPointer(uint32(loc), 4, gl.FLOAT, false, 0, 0)
+		sb.EnableVertexAttribArray(uint32(loc))
+	}
+	sb.BindVertexArray(0)
+
+	return manager
+}
+
+func TestGLManager_SetIndicesType(t *testing.T) {
+	manager := GLManager{}
+
+	manager.SetIndices([]uint32{0, 1, 2, PrimitiveRestart, 3})
+	assert.Equal(t, uint32(gl.UNSIGNED_SHORT), manager.IndexType())
+
+	manager.SetIndices([]uint32{0, 70000})
+	assert.Equal(t, uint32(gl.UNSIGNED_INT), manager.IndexType())
+	assert.ErrorIs(t, manager.SetIndexType(gl.UNSIGNED_SHORT), ErrInvalidIndexType)
+	assert.ErrorIs(t, manager.SetIndexType(gl.FLOAT), ErrInvalidIndexType)
+
+	manager.SetIndices([]uint32{0, 1})
+	assert.NoError(t, manager.SetIndexType(gl.UNSIGNED_INT))
+	assert.Equal(t, uint32(gl.UNSIGNED_INT), manager.IndexType())
+}
+
+func TestGLManager_BindEBO(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
+	manager.SetIndices([]uint32{0, 1, 2, PrimitiveRestart})
+
+	assert.ErrorIs(t, manager.BindEBO(), ErrNoVertexArray)
+
+	manager.BindVBOs()
+	manager.BindVAOs()
+	assert.NoError(t, manager.BindEBO())
+	assert.NoError(t, manager.BindEBO())
+	assert.Equal(t, 3, rec.Count("GenBuffer"))
+
+	// uint16 indices with the restart marker squeezed down to 0xFFFF
+	data := rec.BufferContents[manager.EBO()]
+	if assert.Len(t, data, 8) {
+		assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(data[4:]))
+		assert.Equal(t, uint16(0xFFFF), binary.LittleEndian.Uint16(data[6:]))
+	}
+
+	// The element buffer belongs to the VAO, not the global binding
+	assert.Zero(t, rec.BoundBuffer(gl.ELEMENT_ARRAY_BUFFER))
+	rec.BindVertexArray(manager.VAOs()[0])
+	assert.Equal(t, manager.EBO(), rec.BoundBuffer(gl.ELEMENT_ARRAY_BUFFER))
+
+	manager.Destroy()
+	assert.Zero(t, manager.EBO())
+	assert.Equal(t, 3, rec.Count("DeleteBuffer"))
+}
+
+func TestGLManager_DrawElements(t *testing.T) {
+	sb := NewSoftwareBackend(4, 4)
+	manager := indexedQuad(sb)
+
+	// Four shared vertices make both triangles of the quad
+	manager.SetIndices([]uint32{0, 1, 2, 2, 3, 0})
+	assert.NoError(t, manager.BindEBO())
+	manager.DrawElements(gl.TRIANGLES)
+
+	draws := sb.CallsNamed("DrawElements")
+	if assert.Len(t, draws, 1) {
+		assert.Equal(t, int32(6), draws[0].Args[1])
+		assert.Equal(t, uint32(gl.UNSIGNED_SHORT), draws[0].Args[2])
+	}
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			assert.Equal(t, toRGBA(red), sb.Image().RGBAAt(x, y))
+		}
+	}
+}
+
+func TestGLManager_DrawElementsPrimitiveRestart(t *testing.T) {
+	sb := NewSoftwareBackend(4, 4)
+	manager := indexedQuad(sb)
+
+	// Two separate strips, the second one alone fills the top left half
+	manager.SetIndices([]uint32{0, 1, 1, PrimitiveRestart, 0, 2, 3})
+	manager.SetPrimitiveRestart(true)
+	assert.NoError(t, manager.BindEBO())
+	manager.DrawElements(gl.TRIANGLE_STRIP)
+
+	assert.True(t, sb.Enabled(gl.PRIMITIVE_RESTART))
+	assert.Equal(t, uint32(0xFFFF), sb.RestartIndex())
+
+	// Top left pixel is covered, bottom right isn't
+	assert.Equal(t, toRGBA(red), sb.Image().RGBAAt(0, 0))
+	assert.NotEqual(t, toRGBA(red), sb.Image().RGBAAt(3, 3))
+
+	manager.SetPrimitiveRestart(false)
+	manager.DrawElements(gl.TRIANGLE_STRIP)
+	assert.False(t, sb.Enabled(gl.PRIMITIVE_RESTART))
+}
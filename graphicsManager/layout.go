@@ -0,0 +1,394 @@
+package graphicsManager
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Attribute names the demos' shaders use for Vec4Storage's two streams
+const (
+	PositionAttribute = "aPosition"
+	ColorAttribute    = "aColor"
+)
+
+// VertexAttribute describes one shader input. Data always starts out as
+// mgl32.Vec4, the first Components values are kept and converted to Type.
+type VertexAttribute struct {
+	Name string
+	// Stream is the storage stream the data comes from, Name when empty
+	Stream string
+	// Components is 1 to 4
+	Components int32
+	// Type is gl.FLOAT when left zero, the integer types are read as floats
+	// by the shader, scaled to [0,1] or [-1,1] when Normalized is set
+	Type       uint32
+	Normalized bool
+	// Divisor above zero advances the attribute per instance instead of per vertex
+	Divisor uint32
+}
+
+// VertexLayout is the list of attributes and how they sit in buffers.
+// Interleaved packs every attribute of a vertex next to each other in one
+// buffer, planar gives each attribute its own buffer.
+type VertexLayout struct {
+	Attributes  []VertexAttribute
+	Interleaved bool
+}
+
+func NewVertexLayout(interleaved bool, attributes ...VertexAttribute) VertexLayout {
+	return VertexLayout{Attributes: attributes, Interleaved: interleaved}
+}
+
+// Float4 is the common case, a float vec4 attribute
+func Float4(name string) VertexAttribute {
+	return VertexAttribute{Name: name, Components: 4, Type: gl.FLOAT}
+}
+
+// attribType defaults a zero type to gl.FLOAT
+func (a VertexAttribute) attribType() uint32 {
+	if a.Type == 0 {
+		return gl.FLOAT
+	}
+
+	return a.Type
+}
+
+func (a VertexAttribute) source() string {
+	if a.Stream == "" {
+		return a.Name
+	}
+
+	return a.Stream
+}
+
+// Size is how many bytes one element of the attribute takes
+func (a VertexAttribute) Size() int {
+	return int(a.Components) * typeSize(a.attribType())
+}
+
+func typeSize(xtype uint32) int {
+	switch xtype {
+	case gl.BYTE, gl.UNSIGNED_BYTE:
+		return 1
+	case gl.SHORT, gl.UNSIGNED_SHORT, gl.HALF_FLOAT:
+		return 2
+	}
+
+	return 4
+}
+
+func (a VertexAttribute) validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("%w: vertex attribute without a name", ErrInvalidLayout)
+	}
+	if a.Components < 1 || a.Components > 4 {
+		return fmt.Errorf("%w: %s has %d components, want 1 to 4", ErrInvalidLayout, a.Name, a.Components)
+	}
+	switch a.attribType() {
+	case gl.FLOAT, gl.BYTE, gl.UNSIGNED_BYTE, gl.SHORT, gl.UNSIGNED_SHORT, gl.INT, gl.UNSIGNED_INT:
+	default:
+		return fmt.Errorf("%w: %s has unsupported type %s", ErrInvalidLayout, a.Name, GLEnumName(a.Type))
+	}
+
+	return nil
+}
+
+// Stride is the distance between vertices, for planar layouts it is the
+// stride of attribute i's own buffer
+func (l VertexLayout) Stride(i int) int {
+	if !l.Interleaved {
+		return l.Attributes[i].Size()
+	}
+
+	stride := 0
+	for _, a := range l.Attributes {
+		stride += a.Size()
+	}
+
+	return stride
+}
+
+// Offset is where attribute i starts inside a vertex
+func (l VertexLayout) Offset(i int) int {
+	if !l.Interleaved {
+		return 0
+	}
+
+	offset := 0
+	for _, a := range l.Attributes[:i] {
+		offset += a.Size()
+	}
+
+	return offset
+}
+
+// Buffer is the index into Pack's result that holds attribute i
+func (l VertexLayout) Buffer(i int) int {
+	if l.Interleaved {
+		return 0
+	}
+
+	return i
+}
+
+// Pack turns named streams into buffer bytes, one buffer when interleaved
+// and one per attribute when planar. It also returns the vertex count.
+// Interleaved streams must all be the same length since they share rows.
+func (l VertexLayout) Pack(streams map[string][]mgl32.Vec4) ([][]byte, int, error) {
+	if len(l.Attributes) == 0 {
+		return nil, 0, fmt.Errorf("%w: no attributes", ErrInvalidLayout)
+	}
+
+	count := -1
+	for _, a := range l.Attributes {
+		if err := a.validate(); err != nil {
+			return nil, 0, err
+		}
+		stream, ok := streams[a.source()]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: no data for %s", ErrInvalidLayout, a.source())
+		}
+		if a.Divisor > 0 {
+			if l.Interleaved {
+				// Rows are per vertex, there's no room in them for per instance data
+				return nil, 0, fmt.Errorf("%w: %s is per instance, interleaved layouts only take per vertex attributes", ErrInvalidLayout, a.Name)
+			}
+			continue
+		}
+		if count >= 0 && len(stream) != count {
+			return nil, 0, fmt.Errorf("%w: %s has %d elements, expected %d", ErrInvalidLayout, a.Name, len(stream), count)
+		}
+		count = len(stream)
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	if l.Interleaved {
+		stride := l.Stride(0)
+		data := make([]byte, stride*count)
+		for i, a := range l.Attributes {
+			offset := l.Offset(i)
+			for v, value := range streams[a.source()] {
+				putAttribute(data[v*stride+offset:], a, value)
+			}
+		}
+		return [][]byte{data}, count, nil
+	}
+
+	buffers := make([][]byte, len(l.Attributes))
+	for i, a := range l.Attributes {
+		stream := streams[a.source()]
+		size := a.Size()
+		buffers[i] = make([]byte, size*len(stream))
+		for v, value := range stream {
+			putAttribute(buffers[i][v*size:], a, value)
+		}
+	}
+
+	return buffers, count, nil
+}
+
+// PackRange packs vertices [start, end) the way Pack lays them out and
+// returns the bytes with their offset into the buffer. Interleaved layouts
+// get whole rows, every attribute included, planar ones just attribute i.
+func (l VertexLayout) PackRange(streams map[string][]mgl32.Vec4, i, start, end int) ([]byte, int) {
+	if l.Interleaved {
+		stride := l.Stride(0)
+		data := make([]byte, stride*max(end-start, 0))
+		for j, a := range l.Attributes {
+			stream := streams[a.source()]
+			for v := start; v < end && v < len(stream); v++ {
+				putAttribute(data[(v-start)*stride+l.Offset(j):], a, stream[v])
+			}
+		}
+		return data, start * stride
+	}
+
+	a := l.Attributes[i]
+	stream := streams[a.source()]
+	end = min(end, len(stream))
+	size := a.Size()
+	data := make([]byte, size*max(end-start, 0))
+	for v := start; v < end; v++ {
+		putAttribute(data[(v-start)*size:], a, stream[v])
+	}
+
+	return data, start * size
+}
+
+// putAttribute writes the first Components values of v as the attribute's type
+func putAttribute(dst []byte, a VertexAttribute, v mgl32.Vec4) {
+	xtype := a.attribType()
+	size := typeSize(xtype)
+	for c := 0; c < int(a.Components); c++ {
+		out := dst[c*size:]
+		value := float64(v[c])
+		switch xtype {
+		case gl.FLOAT:
+			binary.LittleEndian.PutUint32(out, math.Float32bits(v[c]))
+		case gl.UNSIGNED_BYTE:
+			out[0] = uint8(toInteger(value, a.Normalized, 0, math.MaxUint8))
+		case gl.BYTE:
+			out[0] = uint8(int8(toInteger(value, a.Normalized, math.MinInt8, math.MaxInt8)))
+		case gl.UNSIGNED_SHORT:
+			binary.LittleEndian.PutUint16(out, uint16(toInteger(value, a.Normalized, 0, math.MaxUint16)))
+		case gl.SHORT:
+			binary.LittleEndian.PutUint16(out, uint16(int16(toInteger(value, a.Normalized, math.MinInt16, math.MaxInt16))))
+		case gl.UNSIGNED_INT:
+			binary.LittleEndian.PutUint32(out, uint32(toInteger(value, a.Normalized, 0, math.MaxUint32)))
+		case gl.INT:
+			binary.LittleEndian.PutUint32(out, uint32(int32(toInteger(value, a.Normalized, math.MinInt32, math.MaxInt32))))
+		}
+	}
+}
+
+// toInteger maps a float into an integer range, normalized values are
+// scaled by the type's max the way GL divides them back out
+func toInteger(value float64, normalized bool, lo, hi float64) int64 {
+	if normalized {
+		value = math.Round(value * hi)
+	}
+
+	return int64(math.Max(lo, math.Min(hi, value)))
+}
+
+// SetVertexLayout picks the layout BindLayout uses
+func (glm *GLManager) SetVertexLayout(layout VertexLayout) {
+	glm.layout = layout
+}
+
+func (glm *GLManager) VertexLayout() VertexLayout {
+	return glm.layout
+}
+
+// VertexCount is how many vertices BindLayout uploaded
+func (glm *GLManager) VertexCount() int {
+	return glm.vertexCount
+}
+
+// vertexStreams is every storage stream, with position and color also
+// under the attribute names the demos' shaders read them with
+func (glm *GLManager) vertexStreams() map[string][]mgl32.Vec4 {
+	streams := glm.storage.Streams()
+	if _, ok := streams[PositionAttribute]; !ok && glm.storage.Has(StreamPosition) {
+		streams[PositionAttribute] = glm.storage.Stream(StreamPosition)
+	}
+	if _, ok := streams[ColorAttribute]; !ok && glm.storage.Has(StreamColor) {
+		streams[ColorAttribute] = glm.storage.Stream(StreamColor)
+	}
+
+	return streams
+}
+
+// LayoutReport is what the last BindLayout couldn't wire up. Neither stops
+// drawing but both usually mean the layout and the shader disagree.
+type LayoutReport struct {
+	// Skipped are layout attributes the program doesn't have, usually
+	// because the compiler dropped them
+	Skipped []string
+	// Validation is what ValidateLayout found, program inputs the layout
+	// doesn't feed
+	Validation error
+}
+
+// Clean reports whether every attribute lined up
+func (r LayoutReport) Clean() bool {
+	return len(r.Skipped) == 0 && r.Validation == nil
+}
+
+func (r LayoutReport) String() string {
+	if r.Clean() {
+		return "layout ok"
+	}
+	var parts []string
+	if len(r.Skipped) > 0 {
+		parts = append(parts, "not used by the program: "+strings.Join(r.Skipped, ", "))
+	}
+	if r.Validation != nil {
+		parts = append(parts, r.Validation.Error())
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// LayoutReport is what the last BindLayout skipped or found missing
+func (glm *GLManager) LayoutReport() LayoutReport {
+	return glm.layoutReport
+}
+
+// BindLayout packs the storage with the vertex layout, uploads it and
+// points every attribute the linked program uses at its data. Attributes
+// the program doesn't have, usually because the compiler dropped them,
+// are skipped and listed in LayoutReport. The VBOs and VAO are reused on
+// later calls.
+func (glm *GLManager) BindLayout() error {
+	if glm.Program == 0 {
+		return ErrNoProgram
+	}
+	layout := glm.layout
+	buffers, count, err := layout.Pack(glm.vertexStreams())
+	if err != nil {
+		return err
+	}
+	b := glm.backend()
+
+	if len(glm.vbos) != len(buffers) {
+		glm.ReleaseVBOs()
+		for range buffers {
+			glm.vbos = append(glm.vbos, b.GenBuffer())
+		}
+	}
+	if len(glm.vaos) == 0 {
+		glm.vaos = append(glm.vaos, makeVao(b, glm.vbos[0]))
+	}
+
+	b.BindVertexArray(glm.vaos[0])
+	for i, data := range buffers {
+		b.BindBuffer(gl.ARRAY_BUFFER, glm.vbos[i])
+		if len(data) > 0 {
+			b.BufferData(gl.ARRAY_BUFFER, len(data), gl.Ptr(data), gl.STATIC_DRAW)
+		} else {
+			b.BufferData(gl.ARRAY_BUFFER, 0, nil, gl.STATIC_DRAW)
+		}
+	}
+
+	var report LayoutReport
+	for i, a := range layout.Attributes {
+		loc, err := glm.Shader().AttribLocation(a.Name)
+		if err != nil {
+			report.Skipped = append(report.Skipped, a.Name)
+			continue
+		}
+		b.BindBuffer(gl.ARRAY_BUFFER, glm.vbos[layout.Buffer(i)])
+		b.VertexAttribPointer(uint32(loc), a.Components, a.attribType(), a.Normalized, int32(layout.Stride(i)), uintptr(layout.Offset(i)))
+		b.VertexAttribDivisor(uint32(loc), a.Divisor)
+		b.EnableVertexAttribArray(uint32(loc))
+	}
+
+	report.Validation = glm.ValidateLayout()
+	glm.layoutReport = report
+
+	b.BindBuffer(gl.ARRAY_BUFFER, 0)
+	b.BindVertexArray(0)
+	glm.vertexCount = count
+	glm.layoutBound = true
+	glm.storage.MarkClean()
+
+	return nil
+}
+
+// DrawArrays draws everything BindLayout uploaded with the first VAO
+func (glm *GLManager) DrawArrays(mode uint32) {
+	if len(glm.vaos) == 0 || glm.vertexCount == 0 {
+		return
+	}
+	b := glm.backend()
+	b.BindVertexArray(glm.vaos[0])
+	b.DrawArrays(mode, 0, int32(glm.vertexCount))
+}
@@ -0,0 +1,128 @@
+package graphicsManager
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVertexLayout_PackInterleaved(t *testing.T) {
+	layout := NewVertexLayout(true,
+		VertexAttribute{Name: PositionAttribute, Components: 3, Type: gl.FLOAT},
+		VertexAttribute{Name: ColorAttribute, Components: 4, Type: gl.UNSIGNED_BYTE, Normalized: true},
+	)
+	assert.Equal(t, 16, layout.Stride(0))
+	assert.Equal(t, 12, layout.Offset(1))
+	assert.Equal(t, 0, layout.Buffer(1))
+
+	buffers, count, err := layout.Pack(map[string][]mgl32.Vec4{
+		PositionAttribute: {{1, 2, 3, 1}, {4, 5, 6, 1}},
+		ColorAttribute:    {{1, 0, 0.5, 1}, {0, 1, 0, 1}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	if assert.Len(t, buffers, 1) && assert.Len(t, buffers[0], 32) {
+		data := buffers[0]
+		assert.Equal(t, float32(4), math.Float32frombits(binary.LittleEndian.Uint32(data[16:])))
+		assert.Equal(t, []byte{255, 0, 128, 255}, data[12:16])
+	}
+}
+
+func TestVertexLayout_PackPlanar(t *testing.T) {
+	layout := NewVertexLayout(false, Float4(PositionAttribute), Float4(ColorAttribute))
+	assert.Equal(t, 16, layout.Stride(1))
+	assert.Equal(t, 0, layout.Offset(1))
+	assert.Equal(t, 1, layout.Buffer(1))
+
+	buffers, count, err := layout.Pack(map[string][]mgl32.Vec4{
+		PositionAttribute: {{1, 2, 3, 1}, {4, 5, 6, 1}},
+		ColorAttribute:    {{1, 0, 0, 1}, {0, 1, 0, 1}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, buffers, 2)
+	assert.Len(t, buffers[1], 32)
+}
+
+func TestVertexLayout_PackErrors(t *testing.T) {
+	streams := map[string][]mgl32.Vec4{
+		PositionAttribute: {{1, 2, 3, 1}, {4, 5, 6, 1}},
+		ColorAttribute:    {{1, 0, 0, 1}},
+	}
+
+	_, _, err := NewVertexLayout(true, Float4(PositionAttribute), Float4(ColorAttribute)).Pack(streams)
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+
+	_, _, err = NewVertexLayout(true, Float4("aNormal")).Pack(streams)
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+
+	_, _, err = NewVertexLayout(true, VertexAttribute{Name: PositionAttribute, Components: 5}).Pack(streams)
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+
+	// A per instance stream doesn't have to match the vertex count
+	instanced := Float4(ColorAttribute)
+	instanced.Divisor = 1
+	_, count, err := NewVertexLayout(false, Float4(PositionAttribute), instanced).Pack(streams)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Interleaved rows are per vertex, per instance data has no place there
+	_, _, err = NewVertexLayout(true, Float4(PositionAttribute), instanced).Pack(streams)
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+}
+
+func TestGLManager_BindLayout(t *testing.T) {
+	rec := &RecordingBackend{Locations: map[string]int32{PositionAttribute: 0}}
+	manager := GLManager{Backend: rec}
+	manager.SetGeoVertices([]mgl32.Vec4{{1, 2, 3, 1}})
+	manager.SetColorVertices([]mgl32.Vec4{{1, 0, 0, 1}})
+	manager.SetVertexLayout(NewVertexLayout(true, Float4(PositionAttribute), Float4(ColorAttribute)))
+
+	assert.ErrorIs(t, manager.BindLayout(), ErrNoProgram)
+
+	manager.Program = 1
+	assert.NoError(t, manager.BindLayout())
+	assert.NoError(t, manager.BindLayout())
+	assert.Len(t, manager.VBOs(), 1)
+	assert.Len(t, manager.VAOs(), 1)
+	assert.Equal(t, 1, rec.Count("GenBuffer"))
+	assert.Equal(t, 1, manager.VertexCount())
+
+	// aColor has no location in this program so only aPosition is wired up
+	pointers := rec.CallsNamed("VertexAttribPointer")
+	if assert.Len(t, pointers, 2) {
+		assert.Equal(t, []any{uint32(0), int32(4), uint32(gl.FLOAT), false, int32(32), uintptr(0)}, pointers[0].Args)
+	}
+	assert.Len(t, rec.BufferContents[manager.VBOs()[0]], 32)
+
+	// The skipped attribute is reported instead of printed
+	report := manager.LayoutReport()
+	assert.Equal(t, []string{ColorAttribute}, report.Skipped)
+	assert.NoError(t, report.Validation)
+	assert.False(t, report.Clean())
+	assert.Contains(t, report.String(), ColorAttribute)
+}
+
+func TestGLManager_BindLayoutRenders(t *testing.T) {
+	for _, interleaved := range []bool{true, false} {
+		sb := NewSoftwareBackend(4, 4)
+		manager := GLManager{Backend: sb, Program: 1}
+		manager.SetGeoVertices([]mgl32.Vec4{
+			{-1, -1, 0, 1}, {1, -1, 0, 1}, {1, 1, 0, 1},
+			{-1, -1, 0, 1}, {1, 1, 0, 1}, {-1, 1, 0, 1},
+		})
+		manager.SetColorVertices([]mgl32.Vec4{green, green, green, green, green, green})
+		manager.SetVertexLayout(NewVertexLayout(interleaved,
+			VertexAttribute{Name: PositionAttribute, Components: 2, Type: gl.FLOAT},
+			VertexAttribute{Name: ColorAttribute, Components: 4, Type: gl.UNSIGNED_BYTE, Normalized: true},
+		))
+
+		assert.NoError(t, manager.BindLayout())
+		manager.DrawArrays(gl.TRIANGLES)
+		assert.Equal(t, toRGBA(green), sb.Image().RGBAAt(1, 2), "interleaved=%v", interleaved)
+	}
+}
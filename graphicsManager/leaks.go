@@ -0,0 +1,214 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resource kinds the leak tracker knows about
+const (
+	ResourceBuffer       = "buffer"
+	ResourceVertexArray  = "vertex array"
+	ResourceShader       = "shader"
+	ResourceProgram      = "program"
+	ResourceTexture      = "texture"
+	ResourceFramebuffer  = "framebuffer"
+	ResourceRenderbuffer = "renderbuffer"
+)
+
+// Resource is one live GPU object and where it was made
+type Resource struct {
+	Kind string
+	Name uint32
+	// Stack is the allocating call stack, innermost frame first
+	Stack string
+}
+
+func (r Resource) String() string {
+	return fmt.Sprintf("%s %d allocated at\n%s", r.Kind, r.Name, r.Stack)
+}
+
+type resourceKey struct {
+	kind string
+	name uint32
+}
+
+// LeakTracker wraps a Backend and remembers every object it creates until
+// the matching delete call. It is what debug builds (-tags gldebug) put in
+// front of the backend in NewGLManager, tests can wrap any backend with it
+// directly and check Live() after teardown.
+type LeakTracker struct {
+	Backend
+
+	mu   sync.Mutex
+	live map[resourceKey][]uintptr
+}
+
+func NewLeakTracker(b Backend) *LeakTracker {
+	return &LeakTracker{Backend: b, live: map[resourceKey][]uintptr{}}
+}
+
+func (t *LeakTracker) created(kind string, name uint32) {
+	if name == 0 {
+		return
+	}
+	// Skip runtime.Callers, created and the tracker method itself
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(3, pcs)]
+
+	t.mu.Lock()
+	t.live[resourceKey{kind, name}] = pcs
+	t.mu.Unlock()
+}
+
+func (t *LeakTracker) deleted(kind string, name uint32) {
+	t.mu.Lock()
+	delete(t.live, resourceKey{kind, name})
+	t.mu.Unlock()
+}
+
+// Live lists every object that hasn't been deleted yet, ordered by kind and name
+func (t *LeakTracker) Live() []Resource {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resources := make([]Resource, 0, len(t.live))
+	for key, pcs := range t.live {
+		resources = append(resources, Resource{Kind: key.kind, Name: key.name, Stack: formatStack(pcs)})
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Kind != resources[j].Kind {
+			return resources[i].Kind < resources[j].Kind
+		}
+		return resources[i].Name < resources[j].Name
+	})
+
+	return resources
+}
+
+// Report writes every live object to w and returns how many there were
+func (t *LeakTracker) Report(w io.Writer) int {
+	live := t.Live()
+	if len(live) == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(w, "graphicsManager: %d GPU resources still alive\n", len(live))
+	for _, r := range live {
+		fmt.Fprintln(w, r)
+	}
+
+	return len(live)
+}
+
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		// Stop at the runtime, nobody needs goexit in a leak report
+		if strings.HasPrefix(frame.Function, "runtime.") || strings.HasPrefix(frame.Function, "testing.") {
+			break
+		}
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+func (t *LeakTracker) GenBuffer() uint32 {
+	name := t.Backend.GenBuffer()
+	t.created(ResourceBuffer, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteBuffer(buffer uint32) {
+	t.deleted(ResourceBuffer, buffer)
+	t.Backend.DeleteBuffer(buffer)
+}
+
+func (t *LeakTracker) GenVertexArray() uint32 {
+	name := t.Backend.GenVertexArray()
+	t.created(ResourceVertexArray, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteVertexArray(vao uint32) {
+	t.deleted(ResourceVertexArray, vao)
+	t.Backend.DeleteVertexArray(vao)
+}
+
+func (t *LeakTracker) CreateShader(shaderType uint32) uint32 {
+	name := t.Backend.CreateShader(shaderType)
+	t.created(ResourceShader, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteShader(shader uint32) {
+	t.deleted(ResourceShader, shader)
+	t.Backend.DeleteShader(shader)
+}
+
+func (t *LeakTracker) CreateProgram() uint32 {
+	name := t.Backend.CreateProgram()
+	t.created(ResourceProgram, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteProgram(program uint32) {
+	t.deleted(ResourceProgram, program)
+	t.Backend.DeleteProgram(program)
+}
+
+func (t *LeakTracker) GenTexture() uint32 {
+	name := t.Backend.GenTexture()
+	t.created(ResourceTexture, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteTexture(texture uint32) {
+	t.deleted(ResourceTexture, texture)
+	t.Backend.DeleteTexture(texture)
+}
+
+func (t *LeakTracker) GenFramebuffer() uint32 {
+	name := t.Backend.GenFramebuffer()
+	t.created(ResourceFramebuffer, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteFramebuffer(framebuffer uint32) {
+	t.deleted(ResourceFramebuffer, framebuffer)
+	t.Backend.DeleteFramebuffer(framebuffer)
+}
+
+func (t *LeakTracker) GenRenderbuffer() uint32 {
+	name := t.Backend.GenRenderbuffer()
+	t.created(ResourceRenderbuffer, name)
+	return name
+}
+
+func (t *LeakTracker) DeleteRenderbuffer(renderbuffer uint32) {
+	t.deleted(ResourceRenderbuffer, renderbuffer)
+	t.Backend.DeleteRenderbuffer(renderbuffer)
+}
+
+// reportLeaks prints what's left when the backend is tracked, called once
+// the manager has freed everything it owns
+func (glm *GLManager) reportLeaks() {
+	b := glm.Backend
+	if checker, ok := b.(*ErrorChecker); ok {
+		b = checker.Backend
+	}
+	if tracker, ok := b.(*LeakTracker); ok {
+		tracker.Report(os.Stdout)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build gldebug
+
+package graphicsManager
+
+// Debug builds track every GPU object NewGLManager's backend creates
+const trackResources = true
@@ -0,0 +1,5 @@
+//go:build !gldebug
+
+package graphicsManager
+
+const trackResources = false
@@ -0,0 +1,57 @@
+package graphicsManager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeakTracker(t *testing.T) {
+	tracker := NewLeakTracker(NewRecordingBackend())
+
+	vbo := tracker.GenBuffer()
+	vao := tracker.GenVertexArray()
+	tracker.DeleteVertexArray(vao)
+
+	live := tracker.Live()
+	if assert.Len(t, live, 1) {
+		assert.Equal(t, ResourceBuffer, live[0].Kind)
+		assert.Equal(t, vbo, live[0].Name)
+		// The stack points back at whoever made the buffer
+		assert.Contains(t, live[0].Stack, "TestLeakTracker")
+		assert.Contains(t, live[0].Stack, "leaks_test.go")
+	}
+
+	var out bytes.Buffer
+	assert.Equal(t, 1, tracker.Report(&out))
+	assert.Contains(t, out.String(), "1 GPU resources still alive")
+
+	tracker.DeleteBuffer(vbo)
+	assert.Empty(t, tracker.Live())
+	assert.Zero(t, tracker.Report(&out))
+}
+
+func TestGLManager_SetupTeardownLeavesNothing(t *testing.T) {
+	provider := &fakeProvider{}
+	glm, err := NewGLManager(WithProvider(provider), WithBackend(NewSoftwareBackend(8, 8)), WithLeakTracking())
+	assert.NoError(t, err)
+	tracker, ok := glm.Backend.(*LeakTracker)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	glm.VS, glm.FS = "vertex", "fragment"
+	assert.NoError(t, glm.SetProgram())
+	glm.SetGeoVertices([]mgl32.Vec4{{-1, -1, 0, 1}, {1, -1, 0, 1}, {0, 1, 0, 1}})
+	glm.SetColorVertices([]mgl32.Vec4{{1, 0, 0, 1}, {0, 1, 0, 1}, {0, 0, 1, 1}})
+	glm.BindVBOs()
+	glm.BindVAOs()
+	_, err = glm.RenderToImage(8, 8)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tracker.Live())
+
+	glm.Terminate()
+	assert.Empty(t, tracker.Live())
+}
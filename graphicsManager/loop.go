@@ -0,0 +1,132 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoopConfig tunes RunLoop, the zero value is 60 updates a second with a
+// 250ms clamp
+type LoopConfig struct {
+	// UpdateRate is how many fixed steps UpdateCall gets per second
+	UpdateRate int
+	// MaxFrameTime is the most time one frame can hand to the updates. A
+	// breakpoint or a dragged window would otherwise leave so much to catch
+	// up on that the updates never do (the spiral of death), the rest of
+	// it is dropped and the simulation runs slow for a frame instead.
+	MaxFrameTime time.Duration
+	// VSync leaves the pacing to SwapBuffers blocking on the display
+	// instead of sleeping, NewGLManager sets it from WithVSync
+	VSync bool
+}
+
+func (c LoopConfig) step() time.Duration {
+	if c.UpdateRate <= 0 {
+		return time.Second / 60
+	}
+
+	return time.Second / time.Duration(c.UpdateRate)
+}
+
+func (c LoopConfig) maxFrameTime() time.Duration {
+	if c.MaxFrameTime <= 0 {
+		return 250 * time.Millisecond
+	}
+
+	return c.MaxFrameTime
+}
+
+// The loop's clock, tests swap these out
+var (
+	loopNow   = time.Now
+	loopSleep = time.Sleep
+)
+
+// RunLoop is where the updating, buffering and rendering take place until
+// the window is closed or Stop is called.
+//
+// UpdateCall runs at the fixed LoopConfig.UpdateRate however fast frames
+// come, as many times per frame as the time since the last one covers.
+// RenderAlphaCall then gets how far the leftover time is into the next
+// step, 0 to 1, to interpolate between the last two updates with. Without
+// it RenderCall is used.
+//
+// fps caps the frame rate, the sleep counts from the start of the frame so
+// rendering is part of the budget. With LoopConfig.VSync set, or fps 0,
+// there is no sleep and SwapBuffers sets the pace.
+func (glm *GLManager) RunLoop(fps int) {
+	// A Stop from before the loop started still counts, the flag is only
+	// cleared once the loop is over
+	defer glm.stopped.Store(false)
+	step := glm.Loop.step()
+	maxFrame := glm.Loop.maxFrameTime()
+	var budget time.Duration
+	if fps > 0 {
+		budget = time.Second / time.Duration(fps)
+	}
+
+	var accumulator time.Duration
+	last := loopNow()
+	for !glm.Context.ShouldClose() && !glm.stopped.Load() {
+		start := loopNow()
+		elapsed := start.Sub(last)
+		last = start
+		if elapsed > maxFrame {
+			elapsed = maxFrame
+		}
+
+		// Input first so the updates see it
+		glm.Context.PollEvents()
+		// Shader edits on disk are relinked here, on the render thread
+		glm.PollShaders()
+
+		if glm.UpdateCall != nil {
+			accumulator += elapsed
+			for accumulator >= step {
+				glm.UpdateCall(step)
+				accumulator -= step
+			}
+		}
+
+		// Edited vertices go up before the frame that shows them
+		if err := glm.FlushDirty(); err != nil {
+			fmt.Println("FlushDirty failed:", err)
+		}
+		glm.render(float64(accumulator) / float64(step))
+		glm.Context.SwapBuffers()
+
+		if budget > 0 && !glm.Loop.VSync {
+			if rest := budget - loopNow().Sub(start); rest > 0 {
+				loopSleep(rest)
+			}
+		}
+	}
+}
+
+// Stop ends RunLoop after the frame it's on, the window stays open. It's
+// safe to call from another goroutine or from the callbacks themselves,
+// called before RunLoop the loop returns without drawing.
+func (glm *GLManager) Stop() {
+	glm.stopped.Store(true)
+}
+
+// SetVSync turns vsync on or off and has RunLoop pace itself to match
+func (glm *GLManager) SetVSync(vsync bool) {
+	if vsync {
+		glm.Context.SetSwapInterval(1)
+	} else {
+		glm.Context.SetSwapInterval(0)
+	}
+	glm.Loop.VSync = vsync
+}
+
+func (glm *GLManager) render(alpha float64) {
+	switch {
+	case glm.RenderAlphaCall != nil:
+		glm.RenderAlphaCall(alpha)
+	case glm.RenderCall != nil:
+		glm.RenderCall()
+	default:
+		fmt.Println("Render call function is nil")
+	}
+}
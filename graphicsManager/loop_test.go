@@ -0,0 +1,134 @@
+package graphicsManager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock only moves when a test moves it or RunLoop sleeps
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func useFakeClock(t *testing.T) *fakeClock {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	now, sleep := loopNow, loopSleep
+	loopNow = func() time.Time { return clock.now }
+	loopSleep = func(d time.Duration) {
+		clock.sleeps = append(clock.sleeps, d)
+		clock.now = clock.now.Add(d)
+	}
+	t.Cleanup(func() { loopNow, loopSleep = now, sleep })
+
+	return clock
+}
+
+func TestGLManager_RunLoopFixedStep(t *testing.T) {
+	clock := useFakeClock(t)
+	ctx := &fakeContext{closeAfter: 4}
+	manager := GLManager{Context: ctx, Backend: &RecordingBackend{}, Loop: LoopConfig{UpdateRate: 100}}
+
+	var steps []time.Duration
+	var alphas []float64
+	manager.UpdateCall = func(dt time.Duration) { steps = append(steps, dt) }
+	manager.RenderAlphaCall = func(alpha float64) {
+		alphas = append(alphas, alpha)
+		// Each frame takes 15ms to render
+		clock.now = clock.now.Add(15 * time.Millisecond)
+	}
+	manager.RunLoop(50)
+
+	// 20ms a frame, 15 rendering and 5 asleep
+	assert.Equal(t, []time.Duration{5 * time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond, 5 * time.Millisecond}, clock.sleeps)
+	// Nothing has passed on the first frame, then 20ms is two 10ms steps
+	assert.Len(t, steps, 6)
+	for _, dt := range steps {
+		assert.Equal(t, 10*time.Millisecond, dt)
+	}
+	assert.Len(t, alphas, 4)
+	for _, alpha := range alphas {
+		assert.InDelta(t, 0, alpha, 1e-9)
+	}
+}
+
+func TestGLManager_RunLoopAlphaAndClamp(t *testing.T) {
+	clock := useFakeClock(t)
+	ctx := &fakeContext{closeAfter: 3}
+	manager := GLManager{Context: ctx, Backend: &RecordingBackend{}, Loop: LoopConfig{UpdateRate: 100, MaxFrameTime: 100 * time.Millisecond}}
+
+	updates := 0
+	var alphas []float64
+	manager.UpdateCall = func(time.Duration) { updates++ }
+	manager.RenderAlphaCall = func(alpha float64) {
+		alphas = append(alphas, alpha)
+		if len(alphas) == 1 {
+			// A 25ms frame leaves half a step over
+			clock.now = clock.now.Add(25 * time.Millisecond)
+		} else {
+			// A 5s stall only gets 100ms worth of updates
+			clock.now = clock.now.Add(5 * time.Second)
+		}
+	}
+	manager.RunLoop(0)
+
+	assert.Empty(t, clock.sleeps)
+	assert.Equal(t, 2+10, updates)
+	assert.InDeltaSlice(t, []float64{0, 0.5, 0.5}, alphas, 1e-9)
+}
+
+func TestGLManager_RunLoopVSyncAndStop(t *testing.T) {
+	clock := useFakeClock(t)
+	ctx := &fakeContext{}
+	manager := GLManager{Context: ctx, Backend: &RecordingBackend{}}
+	manager.SetVSync(true)
+	assert.Equal(t, 1, ctx.swapInterval)
+
+	frames := 0
+	manager.RenderCall = func() {
+		frames++
+		if frames == 3 {
+			manager.Stop()
+		}
+	}
+	manager.RunLoop(60)
+
+	// SwapBuffers paces the loop, there's nothing to sleep for
+	assert.Empty(t, clock.sleeps)
+	assert.Equal(t, 3, frames)
+	assert.Equal(t, 3, ctx.swaps)
+	assert.False(t, ctx.ShouldClose())
+
+	// A stopped loop can be run again
+	manager.SetVSync(false)
+	assert.Equal(t, 0, ctx.swapInterval)
+	ctx.closeAfter = ctx.swaps + 2
+	manager.RunLoop(60)
+	assert.Equal(t, 5, frames)
+	assert.Len(t, clock.sleeps, 2)
+}
+
+func TestGLManager_StopBeforeRunLoop(t *testing.T) {
+	useFakeClock(t)
+	ctx := &fakeContext{closeAfter: 3}
+	manager := GLManager{Context: ctx, Backend: &RecordingBackend{}}
+	frames := 0
+	manager.RenderCall = func() { frames++ }
+
+	// Stopped from another goroutine before the loop got going
+	done := make(chan struct{})
+	go func() {
+		manager.Stop()
+		close(done)
+	}()
+	<-done
+	manager.RunLoop(60)
+	assert.Zero(t, frames)
+	assert.Zero(t, ctx.swaps)
+
+	// The stop was used up, the next run goes until the window closes
+	manager.RunLoop(60)
+	assert.Equal(t, 3, frames)
+}
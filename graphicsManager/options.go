@@ -0,0 +1,217 @@
+package graphicsManager
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Option configures NewGLManager
+type Option func(*managerConfig)
+
+type managerConfig struct {
+	context  ContextConfig
+	vsync    bool
+	provider ContextProvider
+	backend  Backend
+	leaks    bool
+	debug    bool
+	severity DebugSeverity
+}
+
+// WithSize sets the window or surface size in screen coordinates
+func WithSize(width, height int) Option {
+	return func(c *managerConfig) {
+		c.context.Width, c.context.Height = width, height
+	}
+}
+
+func WithTitle(title string) Option {
+	return func(c *managerConfig) {
+		c.context.Title = title
+	}
+}
+
+// WithGLVersion requests a context version, 4.1 when not given
+func WithGLVersion(major, minor int) Option {
+	return func(c *managerConfig) {
+		c.context.VersionMajor, c.context.VersionMinor = major, minor
+	}
+}
+
+// WithCoreProfile picks the core profile when true and lets the driver
+// choose when false. Forward compatibility follows the core profile.
+func WithCoreProfile(core bool) Option {
+	return func(c *managerConfig) {
+		c.context.CoreProfile = core
+		c.context.ForwardCompatible = core
+	}
+}
+
+// WithVSync ties buffer swaps to the display refresh
+func WithVSync(vsync bool) Option {
+	return func(c *managerConfig) {
+		c.vsync = vsync
+	}
+}
+
+// WithSamples asks for an MSAA default framebuffer with n samples per pixel
+func WithSamples(samples int) Option {
+	return func(c *managerConfig) {
+		c.context.Samples = samples
+	}
+}
+
+func WithResizable(resizable bool) Option {
+	return func(c *managerConfig) {
+		c.context.Resizable = resizable
+	}
+}
+
+// WithHidden creates the window without showing it
+func WithHidden() Option {
+	return func(c *managerConfig) {
+		c.context.Hidden = true
+	}
+}
+
+// WithProvider swaps glfw for another context provider, e.g. NewEGLProvider()
+func WithProvider(provider ContextProvider) Option {
+	return func(c *managerConfig) {
+		c.provider = provider
+	}
+}
+
+// WithBackend replaces the OpenGL backend. gl.Init is skipped for anything
+// but GLBackend, which lets tests build a manager without a driver.
+func WithBackend(backend Backend) Option {
+	return func(c *managerConfig) {
+		c.backend = backend
+	}
+}
+
+// WithLeakTracking wraps the backend in a LeakTracker so Destroy reports
+// any GPU object still alive, debug builds (-tags gldebug) always do this
+func WithLeakTracking() Option {
+	return func(c *managerConfig) {
+		c.leaks = true
+	}
+}
+
+// WithDebug asks for a debug context and turns on GL error reporting, see
+// GLManager.EnableDebug. min is the least severe KHR_debug message shown.
+func WithDebug(min DebugSeverity) Option {
+	return func(c *managerConfig) {
+		c.debug = true
+		c.severity = min
+		c.context.Debug = true
+	}
+}
+
+func (c managerConfig) validate() error {
+	switch {
+	case c.context.Width <= 0 || c.context.Height <= 0:
+		return fmt.Errorf("%w: size must be positive, got %dx%d", ErrInvalidConfig, c.context.Width, c.context.Height)
+	case c.context.VersionMajor < 1 || c.context.VersionMinor < 0:
+		return fmt.Errorf("%w: GL version %d.%d", ErrInvalidConfig, c.context.VersionMajor, c.context.VersionMinor)
+	case c.context.Samples < 0:
+		return fmt.Errorf("%w: MSAA samples must not be negative, got %d", ErrInvalidConfig, c.context.Samples)
+	}
+
+	return nil
+}
+
+// NewGLManager creates a context, makes it current, loads the GL functions
+// and returns a manager ready for shaders and geometry. It has to run on
+// the thread locked with runtime.LockOSThread. The defaults are an 800x600
+// resizable window with a 4.1 core forward compatible context from glfw.
+func NewGLManager(opts ...Option) (*GLManager, error) {
+	config := managerConfig{
+		context: DefaultContextConfig(800, 600, "alleviated-wave"),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.provider == nil {
+		config.provider = &GLFWProvider{}
+	}
+
+	ctx, err := config.provider.NewContext(config.context)
+	if err != nil {
+		config.provider.Terminate()
+		return nil, &ContextError{Op: "create context", Err: err}
+	}
+
+	if config.backend == nil {
+		config.backend = GLBackend{}
+	}
+	if needsGLInit(config.backend) {
+		if err := gl.Init(); err != nil {
+			ctx.Destroy()
+			config.provider.Terminate()
+			return nil, &ContextError{Op: "gl.Init", Err: err}
+		}
+	}
+
+	if config.leaks || trackResources {
+		config.backend = NewLeakTracker(config.backend)
+	}
+
+	if config.vsync {
+		ctx.SetSwapInterval(1)
+	} else {
+		ctx.SetSwapInterval(0)
+	}
+	if config.context.Samples > 0 {
+		config.backend.Enable(gl.MULTISAMPLE)
+	}
+
+	glm := &GLManager{
+		Context:  ctx,
+		Backend:  config.backend,
+		provider: config.provider,
+		Loop:     LoopConfig{VSync: config.vsync},
+	}
+	if config.debug {
+		// A callback that won't install leaves the ErrorChecker reporting,
+		// there's still debug output so it isn't a reason to fail
+		glm.EnableDebug(config.severity)
+	}
+
+	return glm, nil
+}
+
+// needsGLInit looks through the wrapping backends for a GLBackend, only the
+// real driver needs its functions loaded
+func needsGLInit(b Backend) bool {
+	for {
+		switch backend := b.(type) {
+		case GLBackend, *GLBackend:
+			return true
+		case *ErrorChecker:
+			b = backend.Backend
+		case *LeakTracker:
+			b = backend.Backend
+		default:
+			return false
+		}
+	}
+}
+
+// Terminate frees the GPU resources, destroys the context and, when
+// NewGLManager created it, shuts down the provider as well
+func (glm *GLManager) Terminate() {
+	if glm.Context != nil {
+		glm.Destroy()
+		glm.Context.Destroy()
+		glm.Context = nil
+	}
+	if glm.provider != nil {
+		glm.provider.Terminate()
+		glm.provider = nil
+	}
+}
@@ -0,0 +1,110 @@
+package graphicsManager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContext is a Context that closes itself after a number of frames
+type fakeContext struct {
+	config       ContextConfig
+	swaps        int
+	closeAfter   int
+	closed       bool
+	swapInterval int
+	destroyed    bool
+}
+
+func (c *fakeContext) MakeCurrent() error { return nil }
+func (c *fakeContext) PollEvents()        {}
+func (c *fakeContext) SwapBuffers() {
+	c.swaps++
+	if c.closeAfter > 0 && c.swaps >= c.closeAfter {
+		c.closed = true
+	}
+}
+func (c *fakeContext) ShouldClose() bool            { return c.closed }
+func (c *fakeContext) SetShouldClose(value bool)    { c.closed = value }
+func (c *fakeContext) FramebufferSize() (int, int)  { return c.config.Width, c.config.Height }
+func (c *fakeContext) SetSwapInterval(interval int) { c.swapInterval = interval }
+func (c *fakeContext) Destroy()                     { c.destroyed = true }
+
+type fakeProvider struct {
+	ctx        *fakeContext
+	err        error
+	terminated bool
+}
+
+func (p *fakeProvider) NewContext(config ContextConfig) (Context, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.ctx = &fakeContext{config: config}
+	return p.ctx, nil
+}
+
+func (p *fakeProvider) Terminate() { p.terminated = true }
+
+func TestNewGLManager(t *testing.T) {
+	provider := &fakeProvider{}
+	rec := NewRecordingBackend()
+
+	glm, err := NewGLManager(
+		WithProvider(provider),
+		WithBackend(rec),
+		WithSize(320, 240),
+		WithTitle("test"),
+		WithGLVersion(4, 3),
+		WithSamples(4),
+		WithVSync(true),
+		WithResizable(false),
+	)
+	assert.NoError(t, err)
+
+	config := provider.ctx.config
+	assert.Equal(t, 320, config.Width)
+	assert.Equal(t, 240, config.Height)
+	assert.Equal(t, "test", config.Title)
+	assert.Equal(t, 4, config.VersionMajor)
+	assert.Equal(t, 3, config.VersionMinor)
+	assert.True(t, config.CoreProfile)
+	assert.False(t, config.Resizable)
+	assert.Equal(t, 1, provider.ctx.swapInterval)
+	assert.True(t, rec.Enabled(gl.MULTISAMPLE))
+
+	glm.Terminate()
+	assert.True(t, provider.ctx.destroyed)
+	assert.True(t, provider.terminated)
+}
+
+func TestNewGLManagerErrors(t *testing.T) {
+	_, err := NewGLManager(WithSize(0, 10), WithProvider(&fakeProvider{}))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewGLManager(WithSamples(-1), WithProvider(&fakeProvider{}))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	failure := errors.New("no display")
+	provider := &fakeProvider{err: failure}
+	_, err = NewGLManager(WithProvider(provider), WithBackend(NewRecordingBackend()))
+
+	var ctxErr *ContextError
+	assert.ErrorAs(t, err, &ctxErr)
+	assert.ErrorIs(t, err, failure)
+	assert.True(t, provider.terminated)
+}
+
+func TestNeedsGLInit(t *testing.T) {
+	assert.True(t, needsGLInit(GLBackend{}))
+	assert.True(t, needsGLInit(&GLBackend{}))
+	// Wrapped GL still has to be loaded
+	assert.True(t, needsGLInit(NewErrorChecker(NewLeakTracker(GLBackend{}), nil)))
+	assert.True(t, needsGLInit(NewLeakTracker(NewErrorChecker(GLBackend{}, nil))))
+
+	assert.False(t, needsGLInit(NewRecordingBackend()))
+	assert.False(t, needsGLInit(NewLeakTracker(NewSoftwareBackend(1, 1))))
+	assert.False(t, needsGLInit(NewErrorChecker(nil, nil)))
+}
@@ -0,0 +1,204 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// DefaultRingRegions is triple buffering, the CPU fills one region while
+// the GPU may still be reading the other two
+const DefaultRingRegions = 3
+
+// FenceTimeout is how long Upload waits for the GPU to let go of a region
+var FenceTimeout = time.Second
+
+// BufferRing is a set of VBOs, one per region, that per frame data is
+// streamed through. Each Upload moves on to the next region, waits for the
+// fence placed there the last time around, then writes with BufferSubData.
+// With Orphan set the region is respecified with a nil BufferData first
+// instead, which lets the driver hand back fresh storage without a wait.
+type BufferRing struct {
+	Orphan bool
+
+	backend  func() Backend
+	target   uint32
+	buffers  []uint32
+	fences   []uintptr
+	capacity []int
+	sizes    []int
+	current  int
+	uploads  int
+}
+
+// BufferPool owns every ring made from it so they can be freed together
+type BufferPool struct {
+	// backend is looked up on every call like ShaderProgram's
+	backend func() Backend
+	rings   []*BufferRing
+}
+
+func NewBufferPool(b Backend) *BufferPool {
+	return &BufferPool{backend: fixedBackend(b)}
+}
+
+// BufferPool is the manager's pool, Destroy releases it
+func (glm *GLManager) BufferPool() *BufferPool {
+	if glm.pool == nil {
+		glm.pool = &BufferPool{backend: glm.backend}
+	}
+
+	return glm.pool
+}
+
+// NewRing makes an ARRAY_BUFFER ring of regions buffers, each starting
+// with capacity bytes of STREAM_DRAW storage. Regions below 1 means
+// DefaultRingRegions.
+func (p *BufferPool) NewRing(regions, capacity int) *BufferRing {
+	if regions < 1 {
+		regions = DefaultRingRegions
+	}
+	r := &BufferRing{
+		backend:  p.backend,
+		target:   gl.ARRAY_BUFFER,
+		buffers:  make([]uint32, regions),
+		fences:   make([]uintptr, regions),
+		capacity: make([]int, regions),
+		sizes:    make([]int, regions),
+		current:  regions - 1,
+	}
+	for i := range r.buffers {
+		r.buffers[i] = p.backend().GenBuffer()
+		if capacity > 0 {
+			p.backend().BindBuffer(r.target, r.buffers[i])
+			p.backend().BufferData(r.target, capacity, nil, gl.STREAM_DRAW)
+			r.capacity[i] = capacity
+		}
+	}
+	p.backend().BindBuffer(r.target, 0)
+	p.rings = append(p.rings, r)
+
+	return r
+}
+
+// Release deletes every ring in the pool
+func (p *BufferPool) Release() {
+	for _, r := range p.rings {
+		r.Release()
+	}
+	p.rings = nil
+}
+
+// Regions is how many buffers the ring cycles through
+func (r *BufferRing) Regions() int {
+	return len(r.buffers)
+}
+
+// Current is the buffer the last Upload wrote, the one to draw from
+func (r *BufferRing) Current() uint32 {
+	return r.buffers[r.current]
+}
+
+// Last is the buffer written the frame before Current
+func (r *BufferRing) Last() uint32 {
+	return r.buffers[(r.current+len(r.buffers)-1)%len(r.buffers)]
+}
+
+// Next is the buffer the following Upload will write
+func (r *BufferRing) Next() uint32 {
+	return r.buffers[(r.current+1)%len(r.buffers)]
+}
+
+// Size is how many bytes the last Upload wrote
+func (r *BufferRing) Size() int {
+	return r.sizes[r.current]
+}
+
+// Upload writes size bytes at data into the next region and returns its
+// buffer, which is left bound to ARRAY_BUFFER. Call Fence after the draw
+// calls that read it.
+func (r *BufferRing) Upload(data unsafe.Pointer, size int) (uint32, error) {
+	next := (r.current + 1) % len(r.buffers)
+	if !r.Orphan {
+		if err := r.wait(next); err != nil {
+			return 0, err
+		}
+	}
+	r.current = next
+	r.uploads++
+
+	b := r.backend()
+	b.BindBuffer(r.target, r.buffers[next])
+	switch {
+	case size > r.capacity[next]:
+		// Too small, grow the region, this allocates so it happens once
+		b.BufferData(r.target, size, data, gl.STREAM_DRAW)
+		r.capacity[next] = size
+	case r.Orphan:
+		b.BufferData(r.target, r.capacity[next], nil, gl.STREAM_DRAW)
+		b.BufferSubData(r.target, 0, size, data)
+	case size > 0:
+		b.BufferSubData(r.target, 0, size, data)
+	}
+	r.sizes[next] = size
+
+	return r.buffers[next], nil
+}
+
+// UploadFloats is Upload for a float32 slice
+func (r *BufferRing) UploadFloats(data []float32) (uint32, error) {
+	if len(data) == 0 {
+		return r.Upload(nil, 0)
+	}
+
+	return r.Upload(gl.Ptr(data), 4*len(data))
+}
+
+// Fence marks the current region as in use until the GPU is done with
+// everything submitted so far
+func (r *BufferRing) Fence() {
+	if r.fences[r.current] != 0 {
+		r.backend().DeleteSync(r.fences[r.current])
+	}
+	r.fences[r.current] = r.backend().FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+}
+
+// wait blocks until region i's fence has signaled
+func (r *BufferRing) wait(i int) error {
+	fence := r.fences[i]
+	if fence == 0 {
+		return nil
+	}
+	status := r.backend().ClientWaitSync(fence, gl.SYNC_FLUSH_COMMANDS_BIT, uint64(FenceTimeout.Nanoseconds()))
+
+	switch status {
+	case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+		r.backend().DeleteSync(fence)
+		r.fences[i] = 0
+		return nil
+	case gl.TIMEOUT_EXPIRED:
+		// The fence stays, the GPU may still be reading the region and the
+		// next Upload has to wait on it again
+		return fmt.Errorf("%w: region %d still busy after %v", ErrFenceTimeout, i, FenceTimeout)
+	}
+
+	return fmt.Errorf("%w: ClientWaitSync returned %s", ErrFenceTimeout, GLEnumName(status))
+}
+
+// Release deletes the buffers and any fences still pending
+func (r *BufferRing) Release() {
+	for i, fence := range r.fences {
+		if fence != 0 {
+			r.backend().DeleteSync(fence)
+			r.fences[i] = 0
+		}
+	}
+	for i, buffer := range r.buffers {
+		if buffer != 0 {
+			r.backend().DeleteBuffer(buffer)
+			r.buffers[i] = 0
+		}
+	}
+}
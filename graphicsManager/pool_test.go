@@ -0,0 +1,98 @@
+package graphicsManager
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferRing_Cycles(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec}
+	ring := manager.BufferPool().NewRing(0, 64)
+	assert.Equal(t, DefaultRingRegions, ring.Regions())
+	assert.Equal(t, 3, rec.Count("GenBuffer"))
+
+	// Each frame lands in the next region and the ring wraps around
+	var written []uint32
+	for frame := 0; frame < 4; frame++ {
+		next := ring.Next()
+		vbo, err := ring.UploadFloats([]float32{float32(frame), 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, next, vbo)
+		assert.Equal(t, vbo, ring.Current())
+		ring.Fence()
+		written = append(written, vbo)
+	}
+	assert.Equal(t, written[0], written[3])
+	assert.Equal(t, written[2], ring.Last())
+	assert.Equal(t, 12, ring.Size())
+
+	// Regions are rewritten in place, the fourth frame waited on the first's fence
+	assert.Equal(t, 4, rec.Count("BufferSubData"))
+	assert.Equal(t, 1, rec.Count("ClientWaitSync"))
+	assert.Equal(t, byte(0x40), rec.BufferContents[written[3]][3]) // 3.0 is 0x40400000
+}
+
+func TestBufferRing_GrowAndOrphan(t *testing.T) {
+	rec := NewRecordingBackend()
+	ring := NewBufferPool(rec).NewRing(2, 0)
+	ring.Orphan = true
+
+	// Nothing allocated up front, the first upload grows the region
+	_, err := ring.UploadFloats([]float32{1, 2, 3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rec.Count("BufferData"))
+	assert.Zero(t, rec.Count("BufferSubData"))
+	ring.Fence()
+
+	ring.UploadFloats([]float32{1, 2, 3, 4})
+	ring.Fence()
+	rec.Reset()
+
+	// Orphaning respecifies the region and never waits on a fence
+	_, err = ring.UploadFloats([]float32{5, 6})
+	assert.NoError(t, err)
+	assert.Zero(t, rec.Count("ClientWaitSync"))
+	data := rec.CallsNamed("BufferData")
+	if assert.Len(t, data, 1) {
+		assert.Equal(t, []any{uint32(gl.ARRAY_BUFFER), 16, uint32(gl.STREAM_DRAW)}, data[0].Args)
+	}
+	assert.Equal(t, 1, rec.Count("BufferSubData"))
+}
+
+func TestBufferRing_FenceTimeout(t *testing.T) {
+	rec := &RecordingBackend{SyncStatus: gl.TIMEOUT_EXPIRED}
+	ring := NewBufferPool(rec).NewRing(1, 16)
+
+	_, err := ring.UploadFloats([]float32{1})
+	assert.NoError(t, err)
+	ring.Fence()
+
+	_, err = ring.UploadFloats([]float32{1})
+	assert.ErrorIs(t, err, ErrFenceTimeout)
+
+	// Still busy, the next Upload waits again instead of writing over it
+	_, err = ring.UploadFloats([]float32{1})
+	assert.ErrorIs(t, err, ErrFenceTimeout)
+	assert.Equal(t, 2, rec.Count("ClientWaitSync"))
+	assert.Zero(t, rec.Count("DeleteSync"))
+
+	// Once it signals the region is written and the fence goes
+	rec.SyncStatus = gl.CONDITION_SATISFIED
+	_, err = ring.UploadFloats([]float32{1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rec.Count("DeleteSync"))
+}
+
+func TestBufferPool_Release(t *testing.T) {
+	tracker := NewLeakTracker(NewRecordingBackend())
+	manager := GLManager{Backend: tracker}
+	ring := manager.BufferPool().NewRing(3, 16)
+	ring.UploadFloats([]float32{1})
+	ring.Fence()
+
+	manager.Destroy()
+	assert.Empty(t, tracker.Live())
+}
@@ -0,0 +1,272 @@
+package graphicsManager
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultGLSLVersion is what a shader without a #version line gets, it
+// matches the 4.1 core context the demos ask for
+const DefaultGLSLVersion = "410"
+
+//go:embed shaders/*.glsl
+var shaderFiles embed.FS
+
+// ShaderLibrary holds the GLSL snippets the demos share, e.g.
+// #include "quaternion.glsl" for multq/invq
+var ShaderLibrary fs.FS = mustSub(shaderFiles, "shaders")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
+
+// Preprocessor expands #include from FS, hoists #version to the top and
+// injects Defines right after it. GLSL has no #include of its own so this
+// runs before the source ever reaches the driver.
+//
+// The driver only ever sees one flat string, ProcessedSource keeps a line
+// table so its "0:12" style locations can be put back into the file they
+// came from. #line isn't used for that, Mesa ignores the source string
+// number on some of its messages.
+type Preprocessor struct {
+	// FS resolves #include "name", relative to the including file first
+	FS fs.FS
+	// Defines become "#define name value" lines, sorted by name
+	Defines map[string]string
+	// Version is used when the shader has no #version of its own
+	Version string
+}
+
+// NewPreprocessor resolves includes from fsys, which may be nil when
+// nothing is included
+func NewPreprocessor(fsys fs.FS) *Preprocessor {
+	return &Preprocessor{FS: fsys, Defines: map[string]string{}, Version: DefaultGLSLVersion}
+}
+
+// Define adds or replaces an injected #define, value can be empty
+func (p *Preprocessor) Define(name, value string) *Preprocessor {
+	if p.Defines == nil {
+		p.Defines = map[string]string{}
+	}
+	p.Defines[name] = value
+
+	return p
+}
+
+// SourceLine is where one line of processed output came from. File is an
+// index into ProcessedSource.Files, -1 for lines the preprocessor wrote.
+type SourceLine struct {
+	File int
+	Line int
+}
+
+// ProcessedSource is the expanded shader plus what's needed to map
+// compiler messages back to the files it was built from
+type ProcessedSource struct {
+	Code string
+	// Files are every file that went in, Files[0] is the shader that was
+	// processed and includes follow in the order they were expanded
+	Files []string
+	// Lines[i] is the origin of output line i+1
+	Lines []SourceLine
+}
+
+// Origin maps a 1 based line of Code back to its file and line
+func (s *ProcessedSource) Origin(line int) SourceLine {
+	if line < 1 || line > len(s.Lines) {
+		return SourceLine{File: -1}
+	}
+
+	return s.Lines[line-1]
+}
+
+// Location names a 1 based line of Code, "quaternion.glsl:3"
+func (s *ProcessedSource) Location(line int) string {
+	origin := s.Origin(line)
+	if origin.File < 0 {
+		return fmt.Sprintf("<preprocessor>:%d", line)
+	}
+
+	return fmt.Sprintf("%s:%d", s.Files[origin.File], origin.Line)
+}
+
+// logLocation matches the "0:12" (Mesa, AMD, Intel) and "0(12)" (NVIDIA)
+// line references compilers put in their info logs
+var logLocation = regexp.MustCompile(`(^|[^\w.])0(?::(\d+)|\((\d+)\))`)
+
+// MapLog rewrites the line references in a compiler info log so they name
+// the original file and line
+func (s *ProcessedSource) MapLog(log string) string {
+	return logLocation.ReplaceAllStringFunc(log, func(m string) string {
+		sub := logLocation.FindStringSubmatch(m)
+		digits := sub[2] + sub[3]
+		line, err := strconv.Atoi(digits)
+		if err != nil {
+			return m
+		}
+
+		return sub[1] + s.Location(line)
+	})
+}
+
+// ProcessFile reads name from FS and expands it
+func (p *Preprocessor) ProcessFile(name string) (*ProcessedSource, error) {
+	if p.FS == nil {
+		return nil, fmt.Errorf("%w: no FS to read %q from", ErrShaderInclude, name)
+	}
+	data, err := fs.ReadFile(p.FS, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrShaderInclude, err)
+	}
+
+	return p.Process(name, string(data))
+}
+
+// Process expands source, name is only used to resolve relative includes
+// and in Files. A trailing "\x00" from the old style constants is dropped,
+// the backend terminates the string when it hands it to GL.
+func (p *Preprocessor) Process(name, source string) (*ProcessedSource, error) {
+	lines := splitSourceLines(source)
+
+	version := p.Version
+	if version == "" {
+		version = DefaultGLSLVersion
+	}
+	for i, line := range lines {
+		if v, ok := directive(line, "version"); ok {
+			version = v
+			lines[i] = ""
+			break
+		}
+	}
+
+	st := &preprocessState{p: p, included: map[string]bool{name: true}}
+	st.emit("#version "+version, SourceLine{File: -1})
+	names := make([]string, 0, len(p.Defines))
+	for n := range p.Defines {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		st.emit(strings.TrimSpace("#define "+n+" "+p.Defines[n]), SourceLine{File: -1})
+	}
+
+	if err := st.expand(name, lines); err != nil {
+		return nil, err
+	}
+
+	return &ProcessedSource{Code: st.out.String(), Files: st.files, Lines: st.lines}, nil
+}
+
+type preprocessState struct {
+	p        *Preprocessor
+	out      strings.Builder
+	files    []string
+	lines    []SourceLine
+	included map[string]bool
+}
+
+func (st *preprocessState) emit(line string, origin SourceLine) {
+	st.out.WriteString(line)
+	st.out.WriteByte('\n')
+	st.lines = append(st.lines, origin)
+}
+
+// expand writes one file with its includes expanded in place
+func (st *preprocessState) expand(name string, lines []string) error {
+	file := len(st.files)
+	st.files = append(st.files, name)
+
+	for i, line := range lines {
+		if _, ok := directive(line, "version"); ok {
+			// Only the top file's #version counts, it's already written
+			st.emit("", SourceLine{File: file, Line: i + 1})
+			continue
+		}
+		arg, ok := directive(line, "include")
+		if !ok {
+			st.emit(line, SourceLine{File: file, Line: i + 1})
+			continue
+		}
+
+		target, err := st.resolve(name, arg)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+		// Every file goes in once, so the helpers need no include guards
+		if st.included[target] {
+			st.emit("", SourceLine{File: file, Line: i + 1})
+			continue
+		}
+		st.included[target] = true
+
+		data, err := fs.ReadFile(st.p.FS, target)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w: %v", name, i+1, ErrShaderInclude, err)
+		}
+		if err := st.expand(target, splitSourceLines(string(data))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolve turns the argument of #include into a path in FS
+func (st *preprocessState) resolve(from, arg string) (string, error) {
+	if len(arg) < 2 || !(arg[0] == '"' && arg[len(arg)-1] == '"' || arg[0] == '<' && arg[len(arg)-1] == '>') {
+		return "", fmt.Errorf("%w: malformed #include %s", ErrShaderInclude, arg)
+	}
+	if st.p.FS == nil {
+		return "", fmt.Errorf("%w: no FS to resolve %s", ErrShaderInclude, arg)
+	}
+	target := arg[1 : len(arg)-1]
+
+	if relative := path.Join(path.Dir(from), target); fs.ValidPath(relative) {
+		if _, err := fs.Stat(st.p.FS, relative); err == nil {
+			return relative, nil
+		}
+	}
+	if _, err := fs.Stat(st.p.FS, target); err != nil {
+		return "", fmt.Errorf("%w: %s not found", ErrShaderInclude, arg)
+	}
+
+	return target, nil
+}
+
+// directive returns the argument of "#name arg", spaces around the # are
+// allowed the way the demos indent their shaders
+func directive(line, name string) (string, bool) {
+	t := strings.TrimSpace(line)
+	if !strings.HasPrefix(t, "#") {
+		return "", false
+	}
+	t = strings.TrimSpace(t[1:])
+	if !strings.HasPrefix(t, name) {
+		return "", false
+	}
+	rest := t[len(name):]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+
+	return strings.TrimSpace(rest), true
+}
+
+func splitSourceLines(source string) []string {
+	source = strings.TrimRight(source, "\x00")
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+
+	return strings.Split(source, "\n")
+}
@@ -0,0 +1,83 @@
+package graphicsManager
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreprocessor_Includes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib/common.glsl": {Data: []byte("#include \"math.glsl\"\nfloat twice(float x) { return 2.0 * x; }\n")},
+		"lib/math.glsl":   {Data: []byte("#version 330\nconst float PI = 3.14159;\n")},
+		"main.vert":       {Data: []byte("  #version 410 core\n#include \"lib/common.glsl\"\n#include <lib/math.glsl>\nvoid main() {}\n")},
+	}
+	pp := NewPreprocessor(fsys).Define("SCALE", "2.0").Define("USE_FOG", "")
+
+	out, err := pp.ProcessFile("main.vert")
+	assert.NoError(t, err)
+	lines := strings.Split(out.Code, "\n")
+	assert.Equal(t, "#version 410 core", lines[0])
+	assert.Equal(t, "#define SCALE 2.0", lines[1])
+	assert.Equal(t, "#define USE_FOG", lines[2])
+	// math.glsl goes in once, its own #version is dropped
+	assert.Equal(t, 1, strings.Count(out.Code, "const float PI"))
+	assert.Equal(t, 1, strings.Count(out.Code, "#version"))
+	assert.NotContains(t, out.Code, "#include")
+	assert.NotContains(t, out.Code, "\x00")
+	assert.Equal(t, []string{"main.vert", "lib/common.glsl", "lib/math.glsl"}, out.Files)
+
+	twice := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "float twice") {
+			twice = i + 1
+		}
+	}
+	assert.Equal(t, SourceLine{File: 1, Line: 2}, out.Origin(twice))
+	assert.Equal(t, "lib/common.glsl:2", out.Location(twice))
+	assert.Equal(t, "main.vert:4", out.Location(len(out.Lines)-1))
+	assert.Equal(t, SourceLine{File: -1}, out.Origin(1))
+}
+
+func TestPreprocessor_DefaultVersion(t *testing.T) {
+	out, err := NewPreprocessor(nil).Process("inline", "void main() {}\n\x00")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.Code, "#version "+DefaultGLSLVersion+"\n"))
+
+	_, err = NewPreprocessor(nil).Process("inline", "#include \"missing.glsl\"\n")
+	assert.ErrorIs(t, err, ErrShaderInclude)
+	_, err = NewPreprocessor(fstest.MapFS{}).Process("inline", "void f() {}\n#include \"missing.glsl\"\n")
+	assert.ErrorIs(t, err, ErrShaderInclude)
+	assert.ErrorContains(t, err, "inline:2")
+	_, err = NewPreprocessor(fstest.MapFS{}).Process("inline", "#include missing.glsl\n")
+	assert.ErrorIs(t, err, ErrShaderInclude)
+}
+
+func TestProcessedSource_MapLog(t *testing.T) {
+	fsys := fstest.MapFS{"bad.glsl": {Data: []byte("// one\nfloat g() {\n  return oops;\n}\n")}}
+	out, err := NewPreprocessor(fsys).Process("fragment", "out vec4 f;\n#include \"bad.glsl\"\nvoid main() {}\n")
+	assert.NoError(t, err)
+
+	// "return oops" is line 5 of the expanded code
+	assert.Equal(t, "bad.glsl:3(9): error: `oops' undeclared", out.MapLog("0:5(9): error: `oops' undeclared"))
+	assert.Equal(t, "bad.glsl:3 : error C1008", out.MapLog("0(5) : error C1008"))
+	assert.Equal(t, "ERROR: fragment:1: 'f'", out.MapLog("ERROR: 0:2: 'f'"))
+	assert.Equal(t, "vec2(0.0, 1.0)", out.MapLog("vec2(0.0, 1.0)"))
+}
+
+func TestGLManager_PreprocessedCompileError(t *testing.T) {
+	rec := &RecordingBackend{FailShaderCompile: true, InfoLog: "0:4(2): error: syntax error"}
+	manager := GLManager{Backend: rec, Preprocessor: NewPreprocessor(ShaderLibrary)}
+	manager.VS = "#include \"quaternion.glsl\"\nvoid main() {}\n"
+	manager.FS = "void main() {}\n"
+
+	err := manager.SetProgram()
+	var compileErr *ShaderCompileError
+	if assert.ErrorAs(t, err, &compileErr) {
+		assert.NotNil(t, compileErr.Processed)
+		assert.Contains(t, compileErr.Source, "vec4 multq")
+		assert.Contains(t, err.Error(), "quaternion.glsl:3(2)")
+	}
+}
@@ -0,0 +1,234 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ShaderProgram wraps a linked program and caches where its uniforms and
+// attributes live, so the demos stop asking GL for the same location
+// every frame. Names the program doesn't have come back as
+// ErrUnknownUniform/ErrUnknownAttribute instead of a write to -1.
+//
+// Like plain glUniform the setters write to the program in use, call Use
+// (or glm.BindProgram) first.
+type ShaderProgram struct {
+	ID uint32
+
+	// backend is looked up on every call, the manager's programs follow
+	// the backend EnableDebug swaps in
+	backend    func() Backend
+	uniforms   map[string]int32
+	attributes map[string]int32
+	// warned keeps the missing name warning to once per name
+	warned map[string]bool
+
+	// What reflection found at link time, see reflection.go
+	activeUniforms   []ActiveVariable
+	activeAttributes []ActiveVariable
+}
+
+// NewShaderProgram wraps an already linked program id
+func NewShaderProgram(b Backend, id uint32) *ShaderProgram {
+	return newShaderProgram(fixedBackend(b), id)
+}
+
+func newShaderProgram(backend func() Backend, id uint32) *ShaderProgram {
+	p := &ShaderProgram{ID: id, backend: backend}
+	p.Refresh()
+
+	return p
+}
+
+// Shader is the ShaderProgram for the current glm.Program, it's rebuilt
+// when SetProgram links a new one so the cached locations never go stale
+func (glm *GLManager) Shader() *ShaderProgram {
+	if glm.shader == nil || glm.shader.ID != glm.Program {
+		glm.shader = newShaderProgram(glm.backend, glm.Program)
+	}
+
+	return glm.shader
+}
+
+// Refresh forgets every cached location and reflects the program again,
+// needed after a relink
+func (p *ShaderProgram) Refresh() {
+	p.uniforms = map[string]int32{}
+	p.attributes = map[string]int32{}
+	p.warned = map[string]bool{}
+	p.reflect()
+}
+
+// Use makes this the current program
+func (p *ShaderProgram) Use() error {
+	if p.ID == 0 {
+		return ErrNoProgram
+	}
+	p.backend().UseProgram(p.ID)
+
+	return nil
+}
+
+// UniformLocation looks name up once and caches the answer, a missing
+// uniform is cached too so it's only asked for once
+func (p *ShaderProgram) UniformLocation(name string) (int32, error) {
+	if p.ID == 0 {
+		return -1, ErrNoProgram
+	}
+	loc, ok := p.uniforms[name]
+	if !ok {
+		loc = p.backend().GetUniformLocation(p.ID, name)
+		p.uniforms[name] = loc
+	}
+	if loc < 0 {
+		p.warn("uniform", name)
+		return -1, fmt.Errorf("%w: %q in program %d", ErrUnknownUniform, name, p.ID)
+	}
+
+	return loc, nil
+}
+
+// AttribLocation is UniformLocation for vertex attributes
+func (p *ShaderProgram) AttribLocation(name string) (int32, error) {
+	if p.ID == 0 {
+		return -1, ErrNoProgram
+	}
+	loc, ok := p.attributes[name]
+	if !ok {
+		loc = p.backend().GetAttribLocation(p.ID, name)
+		p.attributes[name] = loc
+	}
+	if loc < 0 {
+		return -1, fmt.Errorf("%w: %q in program %d", ErrUnknownAttribute, name, p.ID)
+	}
+
+	return loc, nil
+}
+
+// HasUniform reports whether the program has an active uniform called name
+func (p *ShaderProgram) HasUniform(name string) bool {
+	if p.ID == 0 {
+		return false
+	}
+	loc, ok := p.uniforms[name]
+	if !ok {
+		loc = p.backend().GetUniformLocation(p.ID, name)
+		p.uniforms[name] = loc
+	}
+
+	return loc >= 0
+}
+
+// warn prints a missing name once, the GLSL compiler drops uniforms that
+// aren't used so this is usually a typo or dead code in the shader
+func (p *ShaderProgram) warn(kind, name string) {
+	if p.warned[kind+name] {
+		return
+	}
+	p.warned[kind+name] = true
+	fmt.Println("Warning:", kind, name, "is not active in program", p.ID, "(unused, or optimized away by the compiler)")
+}
+
+func (p *ShaderProgram) SetInt(name string, v int32) error {
+	loc, err := p.UniformLocation(name)
+	if err != nil {
+		return err
+	}
+	p.backend().Uniform1i(loc, v)
+
+	return nil
+}
+
+func (p *ShaderProgram) SetFloat(name string, v float32) error {
+	loc, err := p.UniformLocation(name)
+	if err != nil {
+		return err
+	}
+	p.backend().Uniform1f(loc, v)
+
+	return nil
+}
+
+func (p *ShaderProgram) SetVec2(name string, v mgl32.Vec2) error {
+	return p.setFloats(name, v[:], p.backend().Uniform2fv)
+}
+
+func (p *ShaderProgram) SetVec3(name string, v mgl32.Vec3) error {
+	return p.setFloats(name, v[:], p.backend().Uniform3fv)
+}
+
+func (p *ShaderProgram) SetVec4(name string, v mgl32.Vec4) error {
+	return p.setFloats(name, v[:], p.backend().Uniform4fv)
+}
+
+// SetMat3 uploads m as is, mgl32 matrices are already column major
+func (p *ShaderProgram) SetMat3(name string, m mgl32.Mat3) error {
+	return p.setMatrix(name, m[:], p.backend().UniformMatrix3fv)
+}
+
+// SetMat4 uploads m as is, mgl32 matrices are already column major
+func (p *ShaderProgram) SetMat4(name string, m mgl32.Mat4) error {
+	return p.setMatrix(name, m[:], p.backend().UniformMatrix4fv)
+}
+
+// The array setters fill a uniform array from its first element, name is
+// the array itself ("uLights" and "uLights[0]" both work in GL)
+
+func (p *ShaderProgram) SetInts(name string, v []int32) error {
+	if len(v) == 0 {
+		return nil
+	}
+	loc, err := p.UniformLocation(name)
+	if err != nil {
+		return err
+	}
+	p.backend().Uniform1iv(loc, v)
+
+	return nil
+}
+
+func (p *ShaderProgram) SetFloats(name string, v []float32) error {
+	return p.setFloats(name, v, p.backend().Uniform1fv)
+}
+
+func (p *ShaderProgram) SetVec3s(name string, v []mgl32.Vec3) error {
+	return p.setFloats(name, Vec3sAsFloat32(v), p.backend().Uniform3fv)
+}
+
+func (p *ShaderProgram) SetVec4s(name string, v []mgl32.Vec4) error {
+	return p.setFloats(name, Vec4sAsFloat32(v), p.backend().Uniform4fv)
+}
+
+func (p *ShaderProgram) SetMat4s(name string, m []mgl32.Mat4) error {
+	if len(m) == 0 {
+		return nil
+	}
+	floats := unsafe.Slice((*float32)(unsafe.Pointer(&m[0])), 16*len(m))
+
+	return p.setMatrix(name, floats, p.backend().UniformMatrix4fv)
+}
+
+func (p *ShaderProgram) setFloats(name string, v []float32, upload func(int32, []float32)) error {
+	if len(v) == 0 {
+		return nil
+	}
+	loc, err := p.UniformLocation(name)
+	if err != nil {
+		return err
+	}
+	upload(loc, v)
+
+	return nil
+}
+
+func (p *ShaderProgram) setMatrix(name string, v []float32, upload func(int32, bool, []float32)) error {
+	loc, err := p.UniformLocation(name)
+	if err != nil {
+		return err
+	}
+	upload(loc, false, v)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package graphicsManager
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShaderProgram_CachesLocations(t *testing.T) {
+	rec := &RecordingBackend{Locations: map[string]int32{"uTheta": 3, "uModelViewMatrix": 5}}
+	shader := NewShaderProgram(rec, 1)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, shader.SetVec3("uTheta", mgl32.Vec3{1, 2, 3}))
+	}
+	assert.Equal(t, 1, rec.Count("GetUniformLocation"))
+	calls := rec.CallsNamed("Uniform3fv")
+	if assert.Len(t, calls, 3) {
+		assert.Equal(t, []any{int32(3), []float32{1, 2, 3}}, calls[0].Args)
+	}
+
+	ident := mgl32.Ident4()
+	assert.NoError(t, shader.SetMat4("uModelViewMatrix", ident))
+	calls = rec.CallsNamed("UniformMatrix4fv")
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, []any{int32(5), false, ident[:]}, calls[0].Args)
+	}
+
+	shader.Refresh()
+	assert.NoError(t, shader.SetVec3("uTheta", mgl32.Vec3{}))
+	assert.Equal(t, 3, rec.Count("GetUniformLocation"))
+}
+
+func TestShaderProgram_UnknownNames(t *testing.T) {
+	rec := &RecordingBackend{Locations: map[string]int32{"uScale": 0}}
+	shader := NewShaderProgram(rec, 1)
+
+	assert.ErrorIs(t, shader.SetFloat("uScael", 2), ErrUnknownUniform)
+	assert.ErrorIs(t, shader.SetInt("uScael", 2), ErrUnknownUniform)
+	assert.ErrorIs(t, shader.SetMat4s("uBones", []mgl32.Mat4{mgl32.Ident4()}), ErrUnknownUniform)
+	assert.False(t, shader.HasUniform("uScael"))
+	assert.True(t, shader.HasUniform("uScale"))
+	// The miss is cached and nothing was written to -1
+	assert.Equal(t, 3, rec.Count("GetUniformLocation"))
+	assert.Zero(t, rec.Count("Uniform1f"))
+	assert.Zero(t, rec.Count("Uniform1i"))
+
+	_, err := shader.AttribLocation("aNormal")
+	assert.ErrorIs(t, err, ErrUnknownAttribute)
+
+	assert.ErrorIs(t, NewShaderProgram(rec, 0).SetFloat("uScale", 1), ErrNoProgram)
+}
+
+func TestShaderProgram_Arrays(t *testing.T) {
+	rec := &RecordingBackend{Locations: map[string]int32{"uLights": 2, "uBones": 8, "uWeights": 4}}
+	shader := NewShaderProgram(rec, 1)
+
+	assert.NoError(t, shader.SetVec4s("uLights", []mgl32.Vec4{{1, 2, 3, 4}, {5, 6, 7, 8}}))
+	assert.NoError(t, shader.SetFloats("uWeights", []float32{0.25, 0.75}))
+	assert.NoError(t, shader.SetMat4s("uBones", []mgl32.Mat4{mgl32.Ident4(), mgl32.Translate3D(1, 2, 3)}))
+	// Empty arrays don't touch GL at all
+	assert.NoError(t, shader.SetVec3s("uMissing", nil))
+
+	assert.Equal(t, []float32{1, 2, 3, 4, 5, 6, 7, 8}, rec.CallsNamed("Uniform4fv")[0].Args[1])
+	assert.Equal(t, []float32{0.25, 0.75}, rec.CallsNamed("Uniform1fv")[0].Args[1])
+	bones := rec.CallsNamed("UniformMatrix4fv")[0].Args[2].([]float32)
+	if assert.Len(t, bones, 32) {
+		assert.Equal(t, float32(1), bones[16+12])
+	}
+}
+
+func TestGLManager_ShaderFollowsProgram(t *testing.T) {
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec}
+	manager.VS = "vertex"
+	manager.FS = "fragment"
+	assert.NoError(t, manager.SetProgram())
+
+	shader := manager.Shader()
+	assert.Same(t, shader, manager.Shader())
+	assert.Equal(t, manager.Program, shader.ID)
+
+	// Relinking refreshes the same ShaderProgram so callers keep a live one
+	old := manager.Program
+	assert.NoError(t, manager.SetProgram())
+	assert.Same(t, shader, manager.Shader())
+	assert.NotEqual(t, old, shader.ID)
+	assert.Equal(t, manager.Program, shader.ID)
+}
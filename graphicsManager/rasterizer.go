@@ -0,0 +1,327 @@
+package graphicsManager
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Rasterizer draws colored triangles into an image.RGBA on the CPU. It
+// follows the GL pipeline closely enough to stand in for it in tests:
+// Transform plays the vertex shader, triangles are clipped against the
+// view volume in clip space, colors are interpolated perspective correct
+// and the depth buffer uses the GL_LESS test with a clear depth of 1.
+type Rasterizer struct {
+	Width, Height int
+
+	// Transform is applied to every position before clipping
+	Transform mgl32.Mat4
+	// DepthTest mirrors gl.Enable(gl.DEPTH_TEST)
+	DepthTest bool
+	// ClearColor is what Clear fills the color buffer with
+	ClearColor mgl32.Vec4
+	// FlatColor is used for triangles drawn without per vertex colors
+	FlatColor mgl32.Vec4
+
+	// Viewport in GL convention, origin at the bottom left
+	ViewportX, ViewportY, ViewportWidth, ViewportHeight int
+
+	img   *image.RGBA
+	depth []float32
+}
+
+// clipVertex is a vertex after Transform, still in homogeneous clip space
+type clipVertex struct {
+	pos   mgl32.Vec4
+	color mgl32.Vec4
+}
+
+// screenVertex is a clipped vertex after the perspective divide
+type screenVertex struct {
+	x, y, z float32
+	invW    float32
+	color   mgl32.Vec4
+}
+
+func NewRasterizer(width, height int) *Rasterizer {
+	r := &Rasterizer{
+		Width:          width,
+		Height:         height,
+		Transform:      mgl32.Ident4(),
+		ClearColor:     mgl32.Vec4{0, 0, 0, 1},
+		FlatColor:      mgl32.Vec4{1, 1, 1, 1},
+		ViewportWidth:  width,
+		ViewportHeight: height,
+		img:            image.NewRGBA(image.Rect(0, 0, width, height)),
+		depth:          make([]float32, width*height),
+	}
+	r.Clear()
+
+	return r
+}
+
+// Image is the color buffer, row 0 is the top of the picture
+func (r *Rasterizer) Image() *image.RGBA {
+	return r.img
+}
+
+// Depth returns the window space depth stored for a pixel, 1 when untouched
+func (r *Rasterizer) Depth(x, y int) float32 {
+	return r.depth[y*r.Width+x]
+}
+
+// Clear resets both the color and the depth buffer
+func (r *Rasterizer) Clear() {
+	r.ClearColorBuffer()
+	r.ClearDepthBuffer()
+}
+
+func (r *Rasterizer) ClearColorBuffer() {
+	c := toRGBA(r.ClearColor)
+	for i := 0; i < len(r.img.Pix); i += 4 {
+		r.img.Pix[i], r.img.Pix[i+1], r.img.Pix[i+2], r.img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+func (r *Rasterizer) ClearDepthBuffer() {
+	for i := range r.depth {
+		r.depth[i] = 1
+	}
+}
+
+// DrawTriangles draws every three positions as one triangle. colors is
+// either empty, in which case FlatColor is used, or one color per position.
+func (r *Rasterizer) DrawTriangles(positions, colors []mgl32.Vec4) {
+	for i := 0; i+2 < len(positions); i += 3 {
+		r.drawTriangle(r.vertex(positions, colors, i), r.vertex(positions, colors, i+1), r.vertex(positions, colors, i+2))
+	}
+}
+
+// DrawTriangleStrip follows GL_TRIANGLE_STRIP ordering, flipping every
+// other triangle so they all keep the same winding
+func (r *Rasterizer) DrawTriangleStrip(positions, colors []mgl32.Vec4) {
+	for i := 0; i+2 < len(positions); i++ {
+		a, b := i, i+1
+		if i%2 == 1 {
+			a, b = b, a
+		}
+		r.drawTriangle(r.vertex(positions, colors, a), r.vertex(positions, colors, b), r.vertex(positions, colors, i+2))
+	}
+}
+
+// DrawTriangleFan follows GL_TRIANGLE_FAN ordering around the first vertex
+func (r *Rasterizer) DrawTriangleFan(positions, colors []mgl32.Vec4) {
+	for i := 1; i+1 < len(positions); i++ {
+		r.drawTriangle(r.vertex(positions, colors, 0), r.vertex(positions, colors, i), r.vertex(positions, colors, i+1))
+	}
+}
+
+// DrawFloat32Storage draws the flattened positions and colors GLManager
+// keeps in Float32Storage, four floats per vertex for both.
+func (r *Rasterizer) DrawFloat32Storage(storage Float32Storage) {
+	r.DrawTriangles(Float32ToVec4(storage.ObjVecFloats), Float32ToVec4(storage.VertexColorFloats))
+}
+
+// RenderSoftware draws the manager's current storage with r and returns the result
+func (glm *GLManager) RenderSoftware(r *Rasterizer) *image.RGBA {
+	r.DrawFloat32Storage(glm.Float32Storage())
+	return r.Image()
+}
+
+func (r *Rasterizer) vertex(positions, colors []mgl32.Vec4, i int) clipVertex {
+	c := r.FlatColor
+	if i < len(colors) {
+		c = colors[i]
+	}
+
+	return clipVertex{pos: r.Transform.Mul4x1(positions[i]), color: c}
+}
+
+func (r *Rasterizer) drawTriangle(a, b, c clipVertex) {
+	polygon := clipPolygon([]clipVertex{a, b, c})
+	if len(polygon) < 3 {
+		return
+	}
+
+	screen := make([]screenVertex, len(polygon))
+	for i, v := range polygon {
+		screen[i] = r.toScreen(v)
+	}
+
+	// The clipped polygon is convex so a fan covers it
+	for i := 1; i+1 < len(screen); i++ {
+		r.fill(screen[0], screen[i], screen[i+1])
+	}
+}
+
+// clipPlanes are the six sides of the view volume written as dot products
+// with the clip space position, a vertex is inside when the result is >= 0
+var clipPlanes = []mgl32.Vec4{
+	{1, 0, 0, 1},  // x >= -w
+	{-1, 0, 0, 1}, // x <= w
+	{0, 1, 0, 1},  // y >= -w
+	{0, -1, 0, 1}, // y <= w
+	{0, 0, 1, 1},  // z >= -w
+	{0, 0, -1, 1}, // z <= w
+}
+
+// clipPolygon is Sutherland-Hodgman against each plane of the view volume.
+// Interpolating attributes linearly in clip space is what keeps them correct
+// once the divide by w happens.
+func clipPolygon(polygon []clipVertex) []clipVertex {
+	for _, plane := range clipPlanes {
+		if len(polygon) == 0 {
+			return nil
+		}
+
+		var result []clipVertex
+		prev := polygon[len(polygon)-1]
+		prevDist := plane.Dot(prev.pos)
+		for _, cur := range polygon {
+			curDist := plane.Dot(cur.pos)
+			if curDist >= 0 {
+				if prevDist < 0 {
+					result = append(result, lerpClip(prev, cur, prevDist/(prevDist-curDist)))
+				}
+				result = append(result, cur)
+			} else if prevDist >= 0 {
+				result = append(result, lerpClip(prev, cur, prevDist/(prevDist-curDist)))
+			}
+			prev, prevDist = cur, curDist
+		}
+		polygon = result
+	}
+
+	return polygon
+}
+
+func lerpClip(a, b clipVertex, t float32) clipVertex {
+	return clipVertex{
+		pos:   a.pos.Add(b.pos.Sub(a.pos).Mul(t)),
+		color: a.color.Add(b.color.Sub(a.color).Mul(t)),
+	}
+}
+
+func (r *Rasterizer) toScreen(v clipVertex) screenVertex {
+	invW := 1 / v.pos.W()
+	ndcX, ndcY, ndcZ := v.pos.X()*invW, v.pos.Y()*invW, v.pos.Z()*invW
+
+	// GL puts the viewport origin at the bottom left, the image rows start at the top
+	x := float32(r.ViewportX) + (ndcX+1)/2*float32(r.ViewportWidth)
+	y := float32(r.Height) - (float32(r.ViewportY) + (ndcY+1)/2*float32(r.ViewportHeight))
+
+	return screenVertex{
+		x:     x,
+		y:     y,
+		z:     (ndcZ + 1) / 2,
+		invW:  invW,
+		color: v.color.Mul(invW),
+	}
+}
+
+// fill scan converts one screen space triangle, sampling at pixel centers
+func (r *Rasterizer) fill(a, b, c screenVertex) {
+	area := edge(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+	// Keep a consistent winding so the inside test is a single sign check
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+
+	minX := clampInt(int(math.Floor(float64(min3(a.x, b.x, c.x)))), 0, r.Width-1)
+	maxX := clampInt(int(math.Ceil(float64(max3(a.x, b.x, c.x)))), 0, r.Width-1)
+	minY := clampInt(int(math.Floor(float64(min3(a.y, b.y, c.y)))), 0, r.Height-1)
+	maxY := clampInt(int(math.Ceil(float64(max3(a.y, b.y, c.y)))), 0, r.Height-1)
+
+	for py := minY; py <= maxY; py++ {
+		for px := minX; px <= maxX; px++ {
+			sx, sy := float32(px)+0.5, float32(py)+0.5
+
+			w0 := edge(b, c, sx, sy)
+			w1 := edge(c, a, sx, sy)
+			w2 := edge(a, b, sx, sy)
+			if !inside(w0, b, c) || !inside(w1, c, a) || !inside(w2, a, b) {
+				continue
+			}
+
+			l0, l1, l2 := w0/area, w1/area, w2/area
+			z := l0*a.z + l1*b.z + l2*c.z
+
+			idx := py*r.Width + px
+			if r.DepthTest && !(z < r.depth[idx]) {
+				continue
+			}
+
+			// Attributes were divided by w in toScreen, undo it with the
+			// interpolated 1/w to get the perspective correct value
+			invW := l0*a.invW + l1*b.invW + l2*c.invW
+			col := a.color.Mul(l0).Add(b.color.Mul(l1)).Add(c.color.Mul(l2)).Mul(1 / invW)
+
+			if r.DepthTest {
+				r.depth[idx] = z
+			}
+			r.img.SetRGBA(px, py, toRGBA(col))
+		}
+	}
+}
+
+// edge is the signed area of the parallelogram spanned by a->b and a->p
+func edge(a, b screenVertex, px, py float32) float32 {
+	return (b.x-a.x)*(py-a.y) - (b.y-a.y)*(px-a.x)
+}
+
+// inside applies a top-left style tie breaker so pixels on an edge shared by
+// two triangles are only drawn once
+func inside(w float32, a, b screenVertex) bool {
+	if w != 0 {
+		return w > 0
+	}
+	dy := b.y - a.y
+	dx := b.x - a.x
+
+	return dy < 0 || (dy == 0 && dx > 0)
+}
+
+func toRGBA(c mgl32.Vec4) color.RGBA {
+	return color.RGBA{
+		R: unitToByte(c.X()),
+		G: unitToByte(c.Y()),
+		B: unitToByte(c.Z()),
+		A: unitToByte(c.W()),
+	}
+}
+
+func unitToByte(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	}
+
+	return uint8(v*255 + 0.5)
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
@@ -0,0 +1,123 @@
+package graphicsManager
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	red   = mgl32.Vec4{1, 0, 0, 1}
+	green = mgl32.Vec4{0, 1, 0, 1}
+	blue  = mgl32.Vec4{0, 0, 1, 1}
+)
+
+func TestRasterizer_DrawTriangles(t *testing.T) {
+	r := NewRasterizer(32, 32)
+
+	r.DrawTriangles([]mgl32.Vec4{
+		{-1, -1, 0, 1},
+		{1, -1, 0, 1},
+		{0, 1, 0, 1},
+	}, []mgl32.Vec4{red, red, red})
+
+	img := r.Image()
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.RGBAAt(16, 20))
+	// The apex points up so the top corners stay cleared
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, img.RGBAAt(31, 0))
+}
+
+func TestRasterizer_DepthTest(t *testing.T) {
+	r := NewRasterizer(16, 16)
+	r.DepthTest = true
+
+	quad := func(z float32) []mgl32.Vec4 {
+		return []mgl32.Vec4{
+			{-1, -1, z, 1}, {1, -1, z, 1}, {1, 1, z, 1},
+			{-1, -1, z, 1}, {1, 1, z, 1}, {-1, 1, z, 1},
+		}
+	}
+
+	// Near first, the far quad drawn after it has to lose
+	r.DrawTriangles(quad(-0.5), nil)
+	r.FlatColor = blue
+	r.DrawTriangles(quad(0.5), nil)
+
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, r.Image().RGBAAt(8, 8))
+	assert.InDelta(t, 0.25, r.Depth(8, 8), 1e-6)
+
+	// Without the test the last triangle wins
+	r.DepthTest = false
+	r.DrawTriangles(quad(0.5), nil)
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, r.Image().RGBAAt(8, 8))
+}
+
+func TestRasterizer_Clipping(t *testing.T) {
+	r := NewRasterizer(16, 16)
+
+	// A triangle far bigger than the view volume still fills the screen
+	r.DrawTriangles([]mgl32.Vec4{
+		{-10, -10, 0, 1},
+		{30, -10, 0, 1},
+		{-10, 30, 0, 1},
+	}, []mgl32.Vec4{green, green, green})
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, r.Image().RGBAAt(15, 0))
+
+	// Entirely behind the near plane draws nothing
+	r.Clear()
+	r.DrawTriangles([]mgl32.Vec4{
+		{-1, -1, -2, 1},
+		{1, -1, -2, 1},
+		{0, 1, -2, 1},
+	}, nil)
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, r.Image().RGBAAt(8, 8))
+}
+
+func TestRasterizer_PerspectiveCorrectColor(t *testing.T) {
+	r := NewRasterizer(64, 1)
+
+	// One edge of the quad sits four times deeper than the other. The
+	// screen space midpoint is not the midpoint along the surface, so a
+	// perspective correct blend has to lean towards the near color.
+	near, far := float32(1), float32(4)
+	positions := []mgl32.Vec4{
+		{-near, -near, 0, near}, {far, -far, 0, far}, {far, far, 0, far},
+		{-near, -near, 0, near}, {far, far, 0, far}, {-near, near, 0, near},
+	}
+	colors := []mgl32.Vec4{red, blue, blue, red, blue, red}
+	r.DrawTriangles(positions, colors)
+
+	mid := r.Image().RGBAAt(32, 0)
+	assert.Greater(t, mid.R, mid.B)
+	assert.InDelta(t, 204, int(mid.R), 3)
+}
+
+func TestSoftwareBackend_DrawArrays(t *testing.T) {
+	sb := NewSoftwareBackend(16, 16)
+	manager := GLManager{Backend: sb}
+
+	manager.SetGeoVertices([]mgl32.Vec4{{-1, -1, 0, 1}, {1, -1, 0, 1}, {-1, 1, 0, 1}, {1, 1, 0, 1}})
+	manager.SetColorVertices([]mgl32.Vec4{blue, blue, blue, blue})
+	manager.BindVBOs()
+
+	vao := sb.GenVertexArray()
+	sb.BindVertexArray(vao)
+	sb.BindBuffer(gl.ARRAY_BUFFER, manager.VBOs()[0])
+	sb.VertexAttribPointer(uint32(sb.GetAttribLocation(0, "aPosition")), 4, gl.FLOAT, false, 0, 0)
+	sb.EnableVertexAttribArray(SoftwarePositionLocation)
+	sb.BindBuffer(gl.ARRAY_BUFFER, manager.VBOs()[1])
+	sb.VertexAttribPointer(uint32(sb.GetAttribLocation(0, "aColor")), 4, gl.FLOAT, false, 0, 0)
+	sb.EnableVertexAttribArray(SoftwareColorLocation)
+
+	sb.ClearColor(1, 1, 1, 1)
+	sb.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	sb.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, sb.Image().RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, sb.Image().RGBAAt(15, 15))
+	assert.Equal(t, 1, sb.Count("DrawArrays"))
+}
@@ -0,0 +1,628 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Call is a single backend call written down by RecordingBackend
+type Call struct {
+	Name string
+	Args []any
+}
+
+func (c Call) String() string {
+	return fmt.Sprintf("%s%v", c.Name, c.Args)
+}
+
+// RecordingBackend implements Backend without touching a GPU. Every call is
+// appended to Calls, object names are handed out from a counter and buffer
+// uploads are copied so tests can look at what would have reached the driver.
+type RecordingBackend struct {
+	Calls []Call
+
+	// Out, when set, gets one line per call as it happens
+	Out io.Writer
+
+	// Knobs used to drive the failure paths
+	FailShaderCompile bool
+	FailProgramLink   bool
+	InfoLog           string
+
+	// Locations, when set, is the only source of attribute and uniform
+	// locations and anything missing from it reports -1 like the driver would
+	Locations map[string]int32
+
+	// ActiveUniforms and ActiveAttributes are what reflection finds in
+	// every linked program, only Name, Size and Type are used
+	ActiveUniforms   []ActiveVariable
+	ActiveAttributes []ActiveVariable
+
+	// Version, when set, is what GetString(gl.VERSION) reports instead of
+	// "4.1 recording"
+	Version string
+
+	// Extensions is what GetStringi(gl.EXTENSIONS) lists
+	Extensions []string
+
+	// FramebufferStatus, when non zero, is what CheckFramebufferStatus reports
+	FramebufferStatus uint32
+
+	// SyncStatus, when non zero, is what ClientWaitSync reports
+	SyncStatus uint32
+
+	// BufferContents holds the last bytes uploaded to each buffer name
+	BufferContents map[uint32][]byte
+
+	nextName      uint32
+	nextLocation  int32
+	locations     map[string]int32
+	boundBuffers  map[uint32]uint32
+	vaoElements   map[uint32]uint32
+	restartIndex  uint32
+	enabled       map[uint32]bool
+	currentProg   uint32
+	currentVAO    uint32
+	currentFBO    uint32
+	viewport      [4]int32
+	pendingErrors []uint32
+	debugCallback func(DebugMessage)
+}
+
+// NewRecordingBackend returns an empty recorder, the zero value works too
+func NewRecordingBackend() *RecordingBackend {
+	return &RecordingBackend{}
+}
+
+func (r *RecordingBackend) record(name string, args ...any) {
+	call := Call{Name: name, Args: args}
+	r.Calls = append(r.Calls, call)
+	if r.Out != nil {
+		fmt.Fprintln(r.Out, call)
+	}
+}
+
+func (r *RecordingBackend) genName() uint32 {
+	r.nextName++
+	return r.nextName
+}
+
+func (r *RecordingBackend) location(program uint32, name string) int32 {
+	if r.Locations != nil {
+		if loc, ok := r.Locations[name]; ok {
+			return loc
+		}
+		return -1
+	}
+
+	if r.locations == nil {
+		r.locations = map[string]int32{}
+	}
+	key := fmt.Sprintf("%d/%s", program, name)
+	if loc, ok := r.locations[key]; ok {
+		return loc
+	}
+	loc := r.nextLocation
+	r.nextLocation++
+	r.locations[key] = loc
+
+	return loc
+}
+
+// CallsNamed returns every recorded call with the given name in order
+func (r *RecordingBackend) CallsNamed(name string) []Call {
+	var result []Call
+	for _, call := range r.Calls {
+		if call.Name == name {
+			result = append(result, call)
+		}
+	}
+
+	return result
+}
+
+// Count is the number of recorded calls with the given name
+func (r *RecordingBackend) Count(name string) int {
+	return len(r.CallsNamed(name))
+}
+
+// Reset forgets the recorded calls but keeps the object state
+func (r *RecordingBackend) Reset() {
+	r.Calls = nil
+}
+
+// Enabled reports whether a capability was left on by Enable/Disable
+func (r *RecordingBackend) Enabled(capability uint32) bool {
+	return r.enabled[capability]
+}
+
+// CurrentProgram is the program last passed to UseProgram
+func (r *RecordingBackend) CurrentProgram() uint32 {
+	return r.currentProg
+}
+
+// BoundVertexArray is the VAO last passed to BindVertexArray
+func (r *RecordingBackend) BoundVertexArray() uint32 {
+	return r.currentVAO
+}
+
+// BoundFramebuffer is the framebuffer last passed to BindFramebuffer
+func (r *RecordingBackend) BoundFramebuffer() uint32 {
+	return r.currentFBO
+}
+
+// BoundBuffer is the buffer currently bound to target. The element array
+// binding belongs to the bound VAO like it does in GL.
+func (r *RecordingBackend) BoundBuffer(target uint32) uint32 {
+	if target == gl.ELEMENT_ARRAY_BUFFER {
+		return r.vaoElements[r.currentVAO]
+	}
+
+	return r.boundBuffers[target]
+}
+
+// RestartIndex is the value last passed to PrimitiveRestartIndex
+func (r *RecordingBackend) RestartIndex() uint32 {
+	return r.restartIndex
+}
+
+// PushError queues an error code for the next GetError calls to return
+func (r *RecordingBackend) PushError(code uint32) {
+	r.pendingErrors = append(r.pendingErrors, code)
+}
+
+func (r *RecordingBackend) GenBuffer() uint32 {
+	name := r.genName()
+	r.record("GenBuffer", name)
+	return name
+}
+
+func (r *RecordingBackend) BindBuffer(target, buffer uint32) {
+	if r.boundBuffers == nil {
+		r.boundBuffers = map[uint32]uint32{}
+	}
+	if target == gl.ELEMENT_ARRAY_BUFFER {
+		if r.vaoElements == nil {
+			r.vaoElements = map[uint32]uint32{}
+		}
+		r.vaoElements[r.currentVAO] = buffer
+	} else {
+		r.boundBuffers[target] = buffer
+	}
+	r.record("BindBuffer", target, buffer)
+}
+
+func (r *RecordingBackend) BufferData(target uint32, size int, data unsafe.Pointer, usage uint32) {
+	contents := make([]byte, size)
+	if data != nil && size > 0 {
+		copy(contents, unsafe.Slice((*byte)(data), size))
+	}
+	if r.BufferContents == nil {
+		r.BufferContents = map[uint32][]byte{}
+	}
+	r.BufferContents[r.BoundBuffer(target)] = contents
+	r.record("BufferData", target, size, usage)
+}
+
+// BufferSubData patches the recorded contents, growing them if the range
+// runs past the end so a test can't miss an out of bounds write
+func (r *RecordingBackend) BufferSubData(target uint32, offset, size int, data unsafe.Pointer) {
+	if r.BufferContents == nil {
+		r.BufferContents = map[uint32][]byte{}
+	}
+	buffer := r.BoundBuffer(target)
+	contents := r.BufferContents[buffer]
+	if len(contents) < offset+size {
+		contents = append(contents, make([]byte, offset+size-len(contents))...)
+	}
+	if data != nil && size > 0 {
+		copy(contents[offset:], unsafe.Slice((*byte)(data), size))
+	}
+	r.BufferContents[buffer] = contents
+	r.record("BufferSubData", target, offset, size)
+}
+
+func (r *RecordingBackend) DeleteBuffer(buffer uint32) {
+	delete(r.BufferContents, buffer)
+	r.record("DeleteBuffer", buffer)
+}
+
+func (r *RecordingBackend) GenVertexArray() uint32 {
+	name := r.genName()
+	r.record("GenVertexArray", name)
+	return name
+}
+
+func (r *RecordingBackend) BindVertexArray(vao uint32) {
+	r.currentVAO = vao
+	r.record("BindVertexArray", vao)
+}
+
+func (r *RecordingBackend) DeleteVertexArray(vao uint32) {
+	r.record("DeleteVertexArray", vao)
+}
+
+func (r *RecordingBackend) EnableVertexAttribArray(index uint32) {
+	r.record("EnableVertexAttribArray", index)
+}
+
+func (r *RecordingBackend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uintptr) {
+	r.record("VertexAttribPointer", index, size, xtype, normalized, stride, offset)
+}
+
+func (r *RecordingBackend) VertexAttribDivisor(index, divisor uint32) {
+	r.record("VertexAttribDivisor", index, divisor)
+}
+
+func (r *RecordingBackend) CreateShader(shaderType uint32) uint32 {
+	name := r.genName()
+	r.record("CreateShader", shaderType, name)
+	return name
+}
+
+func (r *RecordingBackend) ShaderSource(shader uint32, source string) {
+	r.record("ShaderSource", shader, source)
+}
+
+func (r *RecordingBackend) CompileShader(shader uint32) {
+	r.record("CompileShader", shader)
+}
+
+func (r *RecordingBackend) GetShaderiv(shader, pname uint32) int32 {
+	r.record("GetShaderiv", shader, pname)
+	switch pname {
+	case gl.COMPILE_STATUS:
+		if r.FailShaderCompile {
+			return gl.FALSE
+		}
+		return gl.TRUE
+	case gl.INFO_LOG_LENGTH:
+		return int32(len(r.InfoLog))
+	case gl.ACTIVE_UNIFORMS:
+		return int32(len(r.ActiveUniforms))
+	case gl.ACTIVE_ATTRIBUTES:
+		return int32(len(r.ActiveAttributes))
+	}
+
+	return 0
+}
+
+func (r *RecordingBackend) GetShaderInfoLog(shader uint32) string {
+	r.record("GetShaderInfoLog", shader)
+	return r.InfoLog
+}
+
+func (r *RecordingBackend) DeleteShader(shader uint32) {
+	r.record("DeleteShader", shader)
+}
+
+func (r *RecordingBackend) CreateProgram() uint32 {
+	name := r.genName()
+	r.record("CreateProgram", name)
+	return name
+}
+
+func (r *RecordingBackend) AttachShader(program, shader uint32) {
+	r.record("AttachShader", program, shader)
+}
+
+func (r *RecordingBackend) LinkProgram(program uint32) {
+	r.record("LinkProgram", program)
+}
+
+func (r *RecordingBackend) GetProgramiv(program, pname uint32) int32 {
+	r.record("GetProgramiv", program, pname)
+	switch pname {
+	case gl.LINK_STATUS:
+		if r.FailProgramLink {
+			return gl.FALSE
+		}
+		return gl.TRUE
+	case gl.INFO_LOG_LENGTH:
+		return int32(len(r.InfoLog))
+	case gl.ACTIVE_UNIFORMS:
+		return int32(len(r.ActiveUniforms))
+	case gl.ACTIVE_ATTRIBUTES:
+		return int32(len(r.ActiveAttributes))
+	}
+
+	return 0
+}
+
+func (r *RecordingBackend) GetProgramInfoLog(program uint32) string {
+	r.record("GetProgramInfoLog", program)
+	return r.InfoLog
+}
+
+func (r *RecordingBackend) UseProgram(program uint32) {
+	r.currentProg = program
+	r.record("UseProgram", program)
+}
+
+func (r *RecordingBackend) DeleteProgram(program uint32) {
+	r.record("DeleteProgram", program)
+}
+
+func (r *RecordingBackend) GetAttribLocation(program uint32, name string) int32 {
+	loc := r.location(program, name)
+	r.record("GetAttribLocation", program, name, loc)
+	return loc
+}
+
+func (r *RecordingBackend) GetActiveUniform(program, index uint32) (string, int32, uint32) {
+	r.record("GetActiveUniform", program, index)
+	v := r.ActiveUniforms[index]
+	return v.Name, v.Size, v.Type
+}
+
+func (r *RecordingBackend) GetActiveAttrib(program, index uint32) (string, int32, uint32) {
+	r.record("GetActiveAttrib", program, index)
+	v := r.ActiveAttributes[index]
+	return v.Name, v.Size, v.Type
+}
+
+func (r *RecordingBackend) PatchParameteri(pname uint32, value int32) {
+	r.record("PatchParameteri", pname, value)
+}
+
+func (r *RecordingBackend) DispatchCompute(x, y, z uint32) error {
+	r.record("DispatchCompute", x, y, z)
+	return nil
+}
+
+func (r *RecordingBackend) MemoryBarrier(barriers uint32) error {
+	r.record("MemoryBarrier", barriers)
+	return nil
+}
+
+func (r *RecordingBackend) BindBufferBase(target, index, buffer uint32) {
+	r.record("BindBufferBase", target, index, buffer)
+}
+
+func (r *RecordingBackend) GetUniformLocation(program uint32, name string) int32 {
+	loc := r.location(program, name)
+	r.record("GetUniformLocation", program, name, loc)
+	return loc
+}
+
+func (r *RecordingBackend) Uniform1i(location int32, v int32) {
+	r.record("Uniform1i", location, v)
+}
+
+func (r *RecordingBackend) Uniform1f(location int32, v float32) {
+	r.record("Uniform1f", location, v)
+}
+
+func (r *RecordingBackend) Uniform1iv(location int32, v []int32) {
+	r.record("Uniform1iv", location, append([]int32(nil), v...))
+}
+
+func (r *RecordingBackend) Uniform1fv(location int32, v []float32) {
+	r.record("Uniform1fv", location, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) Uniform2fv(location int32, v []float32) {
+	r.record("Uniform2fv", location, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) Uniform3fv(location int32, v []float32) {
+	r.record("Uniform3fv", location, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) Uniform4fv(location int32, v []float32) {
+	r.record("Uniform4fv", location, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) UniformMatrix3fv(location int32, transpose bool, v []float32) {
+	r.record("UniformMatrix3fv", location, transpose, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) UniformMatrix4fv(location int32, transpose bool, v []float32) {
+	r.record("UniformMatrix4fv", location, transpose, append([]float32(nil), v...))
+}
+
+func (r *RecordingBackend) GenTexture() uint32 {
+	name := r.genName()
+	r.record("GenTexture", name)
+	return name
+}
+
+func (r *RecordingBackend) BindTexture(target, texture uint32) {
+	r.record("BindTexture", target, texture)
+}
+
+func (r *RecordingBackend) TexImage2D(target uint32, level, internalFormat, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	r.record("TexImage2D", target, level, internalFormat, width, height, format, xtype)
+}
+
+func (r *RecordingBackend) TexParameteri(target, pname uint32, param int32) {
+	r.record("TexParameteri", target, pname, param)
+}
+
+func (r *RecordingBackend) DeleteTexture(texture uint32) {
+	r.record("DeleteTexture", texture)
+}
+
+func (r *RecordingBackend) GenFramebuffer() uint32 {
+	name := r.genName()
+	r.record("GenFramebuffer", name)
+	return name
+}
+
+func (r *RecordingBackend) BindFramebuffer(target, framebuffer uint32) {
+	r.currentFBO = framebuffer
+	r.record("BindFramebuffer", target, framebuffer)
+}
+
+func (r *RecordingBackend) FramebufferTexture2D(target, attachment, texTarget, texture uint32, level int32) {
+	r.record("FramebufferTexture2D", target, attachment, texTarget, texture, level)
+}
+
+func (r *RecordingBackend) FramebufferRenderbuffer(target, attachment, renderbufferTarget, renderbuffer uint32) {
+	r.record("FramebufferRenderbuffer", target, attachment, renderbufferTarget, renderbuffer)
+}
+
+func (r *RecordingBackend) CheckFramebufferStatus(target uint32) uint32 {
+	r.record("CheckFramebufferStatus", target)
+	if r.FramebufferStatus != 0 {
+		return r.FramebufferStatus
+	}
+
+	return gl.FRAMEBUFFER_COMPLETE
+}
+
+func (r *RecordingBackend) DeleteFramebuffer(framebuffer uint32) {
+	r.record("DeleteFramebuffer", framebuffer)
+}
+
+func (r *RecordingBackend) GenRenderbuffer() uint32 {
+	name := r.genName()
+	r.record("GenRenderbuffer", name)
+	return name
+}
+
+func (r *RecordingBackend) BindRenderbuffer(target, renderbuffer uint32) {
+	r.record("BindRenderbuffer", target, renderbuffer)
+}
+
+func (r *RecordingBackend) RenderbufferStorage(target, internalFormat uint32, width, height int32) {
+	r.record("RenderbufferStorage", target, internalFormat, width, height)
+}
+
+func (r *RecordingBackend) DeleteRenderbuffer(renderbuffer uint32) {
+	r.record("DeleteRenderbuffer", renderbuffer)
+}
+
+// ReadPixels leaves the destination untouched, there are no pixels to read
+func (r *RecordingBackend) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	r.record("ReadPixels", x, y, width, height, format, xtype)
+}
+
+func (r *RecordingBackend) FenceSync(condition, flags uint32) uintptr {
+	sync := uintptr(r.genName())
+	r.record("FenceSync", condition, flags, sync)
+	return sync
+}
+
+// ClientWaitSync reports SyncStatus, or ALREADY_SIGNALED when that's unset
+func (r *RecordingBackend) ClientWaitSync(sync uintptr, flags uint32, timeout uint64) uint32 {
+	r.record("ClientWaitSync", sync, flags, timeout)
+	if r.SyncStatus != 0 {
+		return r.SyncStatus
+	}
+
+	return gl.ALREADY_SIGNALED
+}
+
+func (r *RecordingBackend) DeleteSync(sync uintptr) {
+	r.record("DeleteSync", sync)
+}
+
+func (r *RecordingBackend) Enable(capability uint32) {
+	if r.enabled == nil {
+		r.enabled = map[uint32]bool{}
+	}
+	r.enabled[capability] = true
+	r.record("Enable", capability)
+}
+
+func (r *RecordingBackend) Disable(capability uint32) {
+	if r.enabled != nil {
+		r.enabled[capability] = false
+	}
+	r.record("Disable", capability)
+}
+
+func (r *RecordingBackend) ClearColor(red, green, blue, alpha float32) {
+	r.record("ClearColor", red, green, blue, alpha)
+}
+
+func (r *RecordingBackend) Clear(mask uint32) {
+	r.record("Clear", mask)
+}
+
+func (r *RecordingBackend) Viewport(x, y, width, height int32) {
+	r.viewport = [4]int32{x, y, width, height}
+	r.record("Viewport", x, y, width, height)
+}
+
+func (r *RecordingBackend) GetViewport() (x, y, width, height int32) {
+	r.record("GetViewport")
+	return r.viewport[0], r.viewport[1], r.viewport[2], r.viewport[3]
+}
+
+func (r *RecordingBackend) DrawArrays(mode uint32, first, count int32) {
+	r.record("DrawArrays", mode, first, count)
+}
+
+func (r *RecordingBackend) DrawElements(mode uint32, count int32, xtype uint32, offset uintptr) {
+	r.record("DrawElements", mode, count, xtype, offset)
+}
+
+func (r *RecordingBackend) PrimitiveRestartIndex(index uint32) {
+	r.restartIndex = index
+	r.record("PrimitiveRestartIndex", index)
+}
+
+func (r *RecordingBackend) GetError() uint32 {
+	r.record("GetError")
+	if len(r.pendingErrors) == 0 {
+		return gl.NO_ERROR
+	}
+	code := r.pendingErrors[0]
+	r.pendingErrors = r.pendingErrors[1:]
+
+	return code
+}
+
+func (r *RecordingBackend) GetString(name uint32) string {
+	r.record("GetString", name)
+	if name == gl.VERSION {
+		if r.Version != "" {
+			return r.Version
+		}
+		return "4.1 recording"
+	}
+
+	return "recording"
+}
+
+func (r *RecordingBackend) GetStringi(name, index uint32) string {
+	r.record("GetStringi", name, index)
+	if name == gl.EXTENSIONS && int(index) < len(r.Extensions) {
+		return r.Extensions[index]
+	}
+
+	return ""
+}
+
+func (r *RecordingBackend) GetIntegerv(pname uint32) int32 {
+	r.record("GetIntegerv", pname)
+	if pname == gl.NUM_EXTENSIONS {
+		return int32(len(r.Extensions))
+	}
+
+	return 0
+}
+
+func (r *RecordingBackend) DebugMessageCallback(callback func(DebugMessage)) error {
+	r.record("DebugMessageCallback", callback != nil)
+	r.debugCallback = callback
+	return nil
+}
+
+func (r *RecordingBackend) DebugMessageControl(source, xtype, severity uint32, enabled bool) error {
+	r.record("DebugMessageControl", source, xtype, severity, enabled)
+	return nil
+}
+
+// EmitDebugMessage hands m to the installed debug callback, the way a
+// driver would from inside the failing call
+func (r *RecordingBackend) EmitDebugMessage(m DebugMessage) {
+	if r.debugCallback != nil {
+		r.debugCallback(m)
+	}
+}
@@ -0,0 +1,153 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// ActiveVariable describes one uniform or attribute the linked program
+// actually uses. Anything the GLSL compiler optimized away won't show up.
+type ActiveVariable struct {
+	Name string
+	// Type is the GL enum, gl.FLOAT_VEC3, gl.FLOAT_MAT4, gl.SAMPLER_2D...
+	Type uint32
+	// Size is the array length, 1 for everything that isn't an array
+	Size int32
+	// Location is -1 for uniforms that live in a uniform block
+	Location int32
+}
+
+var glslTypeNames = map[uint32]string{
+	gl.FLOAT:                   "float",
+	gl.FLOAT_VEC2:              "vec2",
+	gl.FLOAT_VEC3:              "vec3",
+	gl.FLOAT_VEC4:              "vec4",
+	gl.DOUBLE:                  "double",
+	gl.INT:                     "int",
+	gl.INT_VEC2:                "ivec2",
+	gl.INT_VEC3:                "ivec3",
+	gl.INT_VEC4:                "ivec4",
+	gl.UNSIGNED_INT:            "uint",
+	gl.UNSIGNED_INT_VEC2:       "uvec2",
+	gl.UNSIGNED_INT_VEC3:       "uvec3",
+	gl.UNSIGNED_INT_VEC4:       "uvec4",
+	gl.BOOL:                    "bool",
+	gl.BOOL_VEC2:               "bvec2",
+	gl.BOOL_VEC3:               "bvec3",
+	gl.BOOL_VEC4:               "bvec4",
+	gl.FLOAT_MAT2:              "mat2",
+	gl.FLOAT_MAT3:              "mat3",
+	gl.FLOAT_MAT4:              "mat4",
+	gl.FLOAT_MAT2x3:            "mat2x3",
+	gl.FLOAT_MAT2x4:            "mat2x4",
+	gl.FLOAT_MAT3x2:            "mat3x2",
+	gl.FLOAT_MAT3x4:            "mat3x4",
+	gl.FLOAT_MAT4x2:            "mat4x2",
+	gl.FLOAT_MAT4x3:            "mat4x3",
+	gl.SAMPLER_1D:              "sampler1D",
+	gl.SAMPLER_2D:              "sampler2D",
+	gl.SAMPLER_3D:              "sampler3D",
+	gl.SAMPLER_CUBE:            "samplerCube",
+	gl.SAMPLER_2D_SHADOW:       "sampler2DShadow",
+	gl.SAMPLER_2D_ARRAY:        "sampler2DArray",
+	gl.INT_SAMPLER_2D:          "isampler2D",
+	gl.UNSIGNED_INT_SAMPLER_2D: "usampler2D",
+}
+
+// TypeName is the GLSL spelling of Type, handy for labels in a tweak panel
+func (v ActiveVariable) TypeName() string {
+	if name, ok := glslTypeNames[v.Type]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("0x%X", v.Type)
+}
+
+func (v ActiveVariable) String() string {
+	if v.Size > 1 {
+		return fmt.Sprintf("%s %s[%d] @%d", v.TypeName(), v.Name, v.Size, v.Location)
+	}
+
+	return fmt.Sprintf("%s %s @%d", v.TypeName(), v.Name, v.Location)
+}
+
+// Uniforms lists the active uniforms found when the program was wrapped
+func (p *ShaderProgram) Uniforms() []ActiveVariable {
+	return p.activeUniforms
+}
+
+// Attributes lists the active vertex attributes, built-ins like
+// gl_VertexID are left out since nothing can be bound to them
+func (p *ShaderProgram) Attributes() []ActiveVariable {
+	return p.activeAttributes
+}
+
+// reflect asks GL for every active uniform and attribute and primes the
+// location caches with them so the setters don't have to ask again
+func (p *ShaderProgram) reflect() {
+	p.activeUniforms = nil
+	p.activeAttributes = nil
+	if p.ID == 0 {
+		return
+	}
+
+	count := p.backend().GetProgramiv(p.ID, gl.ACTIVE_UNIFORMS)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, size, xtype := p.backend().GetActiveUniform(p.ID, i)
+		// Arrays come back as "uLights[0]", the setters take the bare name
+		name = strings.TrimSuffix(name, "[0]")
+		loc := p.backend().GetUniformLocation(p.ID, name)
+		p.uniforms[name] = loc
+		p.activeUniforms = append(p.activeUniforms, ActiveVariable{Name: name, Type: xtype, Size: size, Location: loc})
+	}
+
+	count = p.backend().GetProgramiv(p.ID, gl.ACTIVE_ATTRIBUTES)
+	for i := uint32(0); i < uint32(count); i++ {
+		name, size, xtype := p.backend().GetActiveAttrib(p.ID, i)
+		if strings.HasPrefix(name, "gl_") {
+			continue
+		}
+		loc := p.backend().GetAttribLocation(p.ID, name)
+		p.attributes[name] = loc
+		p.activeAttributes = append(p.activeAttributes, ActiveVariable{Name: name, Type: xtype, Size: size, Location: loc})
+	}
+}
+
+// ActiveUniforms is Shader().Uniforms(), nil before SetProgram
+func (glm *GLManager) ActiveUniforms() []ActiveVariable {
+	return glm.Shader().Uniforms()
+}
+
+// ActiveAttributes is Shader().Attributes(), nil before SetProgram
+func (glm *GLManager) ActiveAttributes() []ActiveVariable {
+	return glm.Shader().Attributes()
+}
+
+// ValidateLayout checks that the vertex layout feeds every attribute the
+// program reads. An attribute left out would silently read a constant.
+func (glm *GLManager) ValidateLayout() error {
+	if glm.Program == 0 {
+		return ErrNoProgram
+	}
+
+	var missing []string
+	for _, attrib := range glm.ActiveAttributes() {
+		found := false
+		for _, a := range glm.layout.Attributes {
+			if a.Name == attrib.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, attrib.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: program reads %s but the layout doesn't provide it", ErrInvalidLayout, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package graphicsManager
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func reflectingBackend() *RecordingBackend {
+	return &RecordingBackend{
+		Locations: map[string]int32{"uModelViewMatrix": 0, "uLights": 1, PositionAttribute: 0, ColorAttribute: 1},
+		ActiveUniforms: []ActiveVariable{
+			{Name: "uModelViewMatrix", Type: gl.FLOAT_MAT4, Size: 1},
+			{Name: "uLights[0]", Type: gl.FLOAT_VEC4, Size: 4},
+		},
+		ActiveAttributes: []ActiveVariable{
+			{Name: PositionAttribute, Type: gl.FLOAT_VEC4, Size: 1},
+			{Name: ColorAttribute, Type: gl.FLOAT_VEC4, Size: 1},
+			{Name: "gl_VertexID", Type: gl.INT, Size: 1},
+		},
+	}
+}
+
+func TestGLManager_ActiveVariables(t *testing.T) {
+	rec := reflectingBackend()
+	manager := GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+	assert.Empty(t, manager.ActiveUniforms())
+	assert.NoError(t, manager.SetProgram())
+
+	uniforms := manager.ActiveUniforms()
+	if assert.Len(t, uniforms, 2) {
+		assert.Equal(t, ActiveVariable{Name: "uModelViewMatrix", Type: gl.FLOAT_MAT4, Size: 1, Location: 0}, uniforms[0])
+		assert.Equal(t, "uLights", uniforms[1].Name)
+		assert.Equal(t, "vec4 uLights[4] @1", uniforms[1].String())
+		assert.Equal(t, "mat4", uniforms[0].TypeName())
+	}
+	attributes := manager.ActiveAttributes()
+	if assert.Len(t, attributes, 2) {
+		assert.Equal(t, ColorAttribute, attributes[1].Name)
+		assert.Equal(t, int32(1), attributes[1].Location)
+	}
+
+	// Reflection already filled the cache, the setters don't ask GL again
+	before := rec.Count("GetUniformLocation")
+	assert.NoError(t, manager.Shader().SetMat4("uModelViewMatrix", mgl32.Ident4()))
+	assert.NoError(t, manager.Shader().SetVec4s("uLights", make([]mgl32.Vec4, 4)))
+	assert.Equal(t, before, rec.Count("GetUniformLocation"))
+}
+
+func TestGLManager_ValidateLayout(t *testing.T) {
+	manager := GLManager{Backend: reflectingBackend(), VS: "vertex", FS: "fragment"}
+	assert.ErrorIs(t, manager.ValidateLayout(), ErrNoProgram)
+	assert.NoError(t, manager.SetProgram())
+
+	manager.SetVertexLayout(NewVertexLayout(true, Float4(PositionAttribute)))
+	err := manager.ValidateLayout()
+	assert.ErrorIs(t, err, ErrInvalidLayout)
+	assert.ErrorContains(t, err, ColorAttribute)
+
+	// BindLayout keeps the same error in its report
+	manager.SetGeoVertices([]mgl32.Vec4{{1, 2, 3, 1}})
+	assert.NoError(t, manager.BindLayout())
+	assert.ErrorIs(t, manager.LayoutReport().Validation, ErrInvalidLayout)
+
+	manager.SetVertexLayout(NewVertexLayout(true, Float4(PositionAttribute), Float4(ColorAttribute)))
+	assert.NoError(t, manager.ValidateLayout())
+}
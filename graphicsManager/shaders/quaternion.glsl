@@ -0,0 +1,27 @@
+// Quaternion helpers, a quaternion is vec4(w, x, y, z)
+
+// quaternion multiplier
+vec4 multq(vec4 a, vec4 b)
+{
+	return (vec4(a.x*b.x - dot(a.yzw, b.yzw), a.x*b.yzw+b.x*a.yzw+cross(b.yzw, a.yzw)));
+}
+
+// inverse quaternion
+vec4 invq(vec4 a)
+{
+	return (vec4(a.x, -a.yzw)/dot(a,a));
+}
+
+// rotq rotates p by the x, y and z angles in degrees, x is applied last
+vec3 rotq(vec3 p, vec3 theta)
+{
+	vec3 angles = radians(theta);
+	vec3 c = cos(angles/2.0);
+	vec3 s = sin(angles/2.0);
+	vec4 rx = vec4(c.x, -s.x, 0.0, 0.0); // x rot quat
+	vec4 ry = vec4(c.y, 0.0, s.y, 0.0); // y rot quat
+	vec4 rz = vec4(c.z, 0.0, 0.0, s.z); // z rot quat
+	vec4 r = multq(rx, multq(ry, rz)); // rot quat
+	vec4 q = multq(r, multq(vec4(0.0, p), invq(r))); // rotated point quat
+	return q.yzw;
+}
@@ -0,0 +1,323 @@
+package graphicsManager
+
+import (
+	"encoding/binary"
+	"image"
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Attribute locations the software backend reports for the names the demos use
+const (
+	SoftwarePositionLocation = 0
+	SoftwareColorLocation    = 1
+)
+
+// attribPointer is what VertexAttribPointer captured for one location
+type attribPointer struct {
+	buffer     uint32
+	size       int32
+	xtype      uint32
+	normalized bool
+	stride     int32
+	offset     uintptr
+	enabled    bool
+}
+
+// SoftwareBackend is a RecordingBackend that also rasterizes its draw calls.
+// Position comes from the attribute at SoftwarePositionLocation, color from
+// SoftwareColorLocation, and Rasterizer.Transform stands in for the vertex
+// shader since GLSL can't run here. It lets the same GLManager code that
+// drives a window produce pixels on a machine without a GPU.
+type SoftwareBackend struct {
+	*RecordingBackend
+	Rasterizer *Rasterizer
+
+	// Attribute names mapped onto the two locations above
+	PositionAttrib string
+	ColorAttrib    string
+
+	vaoAttribs map[uint32]map[uint32]*attribPointer
+}
+
+func NewSoftwareBackend(width, height int) *SoftwareBackend {
+	return &SoftwareBackend{
+		RecordingBackend: NewRecordingBackend(),
+		Rasterizer:       NewRasterizer(width, height),
+		PositionAttrib:   PositionAttribute,
+		ColorAttrib:      ColorAttribute,
+	}
+}
+
+// Image is the current color buffer
+func (s *SoftwareBackend) Image() *image.RGBA {
+	return s.Rasterizer.Image()
+}
+
+func (s *SoftwareBackend) attrib(index uint32) *attribPointer {
+	if s.vaoAttribs == nil {
+		s.vaoAttribs = map[uint32]map[uint32]*attribPointer{}
+	}
+	vao := s.BoundVertexArray()
+	if s.vaoAttribs[vao] == nil {
+		s.vaoAttribs[vao] = map[uint32]*attribPointer{}
+	}
+	if s.vaoAttribs[vao][index] == nil {
+		s.vaoAttribs[vao][index] = &attribPointer{}
+	}
+
+	return s.vaoAttribs[vao][index]
+}
+
+func (s *SoftwareBackend) GetAttribLocation(program uint32, name string) int32 {
+	loc := int32(-1)
+	switch name {
+	case s.PositionAttrib:
+		loc = SoftwarePositionLocation
+	case s.ColorAttrib:
+		loc = SoftwareColorLocation
+	}
+	s.record("GetAttribLocation", program, name, loc)
+
+	return loc
+}
+
+func (s *SoftwareBackend) EnableVertexAttribArray(index uint32) {
+	s.attrib(index).enabled = true
+	s.RecordingBackend.EnableVertexAttribArray(index)
+}
+
+func (s *SoftwareBackend) VertexAttribPointer(index uint32, size int32, xtype uint32, normalized bool, stride int32, offset uintptr) {
+	p := s.attrib(index)
+	p.buffer = s.BoundBuffer(gl.ARRAY_BUFFER)
+	p.size, p.xtype, p.normalized, p.stride, p.offset = size, xtype, normalized, stride, offset
+	s.RecordingBackend.VertexAttribPointer(index, size, xtype, normalized, stride, offset)
+}
+
+func (s *SoftwareBackend) Enable(capability uint32) {
+	if capability == gl.DEPTH_TEST {
+		s.Rasterizer.DepthTest = true
+	}
+	s.RecordingBackend.Enable(capability)
+}
+
+func (s *SoftwareBackend) Disable(capability uint32) {
+	if capability == gl.DEPTH_TEST {
+		s.Rasterizer.DepthTest = false
+	}
+	s.RecordingBackend.Disable(capability)
+}
+
+func (s *SoftwareBackend) ClearColor(red, green, blue, alpha float32) {
+	s.Rasterizer.ClearColor = mgl32.Vec4{red, green, blue, alpha}
+	s.RecordingBackend.ClearColor(red, green, blue, alpha)
+}
+
+func (s *SoftwareBackend) Clear(mask uint32) {
+	if mask&gl.COLOR_BUFFER_BIT != 0 {
+		s.Rasterizer.ClearColorBuffer()
+	}
+	if mask&gl.DEPTH_BUFFER_BIT != 0 {
+		s.Rasterizer.ClearDepthBuffer()
+	}
+	s.RecordingBackend.Clear(mask)
+}
+
+func (s *SoftwareBackend) Viewport(x, y, width, height int32) {
+	s.Rasterizer.ViewportX, s.Rasterizer.ViewportY = int(x), int(y)
+	s.Rasterizer.ViewportWidth, s.Rasterizer.ViewportHeight = int(width), int(height)
+	s.RecordingBackend.Viewport(x, y, width, height)
+}
+
+// GetViewport comes from the rasterizer, it starts out covering the whole image
+func (s *SoftwareBackend) GetViewport() (x, y, width, height int32) {
+	s.RecordingBackend.GetViewport()
+	r := s.Rasterizer
+	return int32(r.ViewportX), int32(r.ViewportY), int32(r.ViewportWidth), int32(r.ViewportHeight)
+}
+
+func (s *SoftwareBackend) DrawArrays(mode uint32, first, count int32) {
+	s.RecordingBackend.DrawArrays(mode, first, count)
+
+	positions := s.fetch(SoftwarePositionLocation, first, count, mgl32.Vec4{0, 0, 0, 1})
+	if positions == nil {
+		return
+	}
+	colors := s.fetch(SoftwareColorLocation, first, count, mgl32.Vec4{0, 0, 0, 1})
+
+	s.draw(mode, positions, colors)
+}
+
+// DrawElements pulls vertices through the element array buffer of the bound
+// VAO, with primitive restart each run between restart indices is its own draw
+func (s *SoftwareBackend) DrawElements(mode uint32, count int32, xtype uint32, offset uintptr) {
+	s.RecordingBackend.DrawElements(mode, count, xtype, offset)
+
+	indices := s.elements(count, xtype, offset)
+	restart := s.Enabled(gl.PRIMITIVE_RESTART)
+
+	start := 0
+	for i := 0; i <= len(indices); i++ {
+		if i < len(indices) && !(restart && indices[i] == s.RestartIndex()) {
+			continue
+		}
+		s.drawIndexed(mode, indices[start:i])
+		start = i + 1
+	}
+}
+
+func (s *SoftwareBackend) drawIndexed(mode uint32, indices []uint32) {
+	if len(indices) == 0 {
+		return
+	}
+
+	positions := make([]mgl32.Vec4, 0, len(indices))
+	colors := make([]mgl32.Vec4, 0, len(indices))
+	for _, index := range indices {
+		position := s.fetch(SoftwarePositionLocation, int32(index), 1, mgl32.Vec4{0, 0, 0, 1})
+		if len(position) == 0 {
+			return
+		}
+		positions = append(positions, position[0])
+		if color := s.fetch(SoftwareColorLocation, int32(index), 1, mgl32.Vec4{0, 0, 0, 1}); len(color) > 0 {
+			colors = append(colors, color[0])
+		}
+	}
+	if len(colors) != len(positions) {
+		colors = nil
+	}
+
+	s.draw(mode, positions, colors)
+}
+
+// elements decodes count indices from the bound element array buffer
+func (s *SoftwareBackend) elements(count int32, xtype uint32, offset uintptr) []uint32 {
+	data := s.BufferContents[s.BoundBuffer(gl.ELEMENT_ARRAY_BUFFER)]
+
+	size := 4
+	switch xtype {
+	case gl.UNSIGNED_BYTE:
+		size = 1
+	case gl.UNSIGNED_SHORT:
+		size = 2
+	}
+
+	indices := make([]uint32, 0, count)
+	for i := 0; i < int(count); i++ {
+		at := int(offset) + i*size
+		if at+size > len(data) {
+			break
+		}
+		switch size {
+		case 1:
+			indices = append(indices, uint32(data[at]))
+		case 2:
+			indices = append(indices, uint32(binary.LittleEndian.Uint16(data[at:])))
+		default:
+			indices = append(indices, binary.LittleEndian.Uint32(data[at:]))
+		}
+	}
+
+	return indices
+}
+
+func (s *SoftwareBackend) draw(mode uint32, positions, colors []mgl32.Vec4) {
+	switch mode {
+	case gl.TRIANGLES:
+		s.Rasterizer.DrawTriangles(positions, colors)
+	case gl.TRIANGLE_STRIP:
+		s.Rasterizer.DrawTriangleStrip(positions, colors)
+	case gl.TRIANGLE_FAN:
+		s.Rasterizer.DrawTriangleFan(positions, colors)
+	}
+}
+
+// fetch reads count vertices for one attribute the way the vertex puller
+// would, missing components come from defaults like in GLSL
+func (s *SoftwareBackend) fetch(index uint32, first, count int32, defaults mgl32.Vec4) []mgl32.Vec4 {
+	p := s.attrib(index)
+	if !p.enabled || p.xtype == gl.HALF_FLOAT {
+		return nil
+	}
+	data := s.BufferContents[p.buffer]
+	size := typeSize(p.xtype)
+
+	stride := int(p.stride)
+	if stride == 0 {
+		stride = int(p.size) * size
+	}
+
+	result := make([]mgl32.Vec4, 0, count)
+	for i := int(first); i < int(first+count); i++ {
+		v := defaults
+		base := int(p.offset) + i*stride
+		for c := 0; c < int(p.size) && c < 4; c++ {
+			at := base + c*size
+			if at+size > len(data) {
+				return result
+			}
+			v[c] = p.component(data[at:])
+		}
+		result = append(result, v)
+	}
+
+	return result
+}
+
+// component converts one value to float, normalized integers are divided
+// by their type's max like GL does
+func (p *attribPointer) component(data []byte) float32 {
+	var value, scale float64
+	switch p.xtype {
+	case gl.FLOAT:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data))
+	case gl.UNSIGNED_BYTE:
+		value, scale = float64(data[0]), math.MaxUint8
+	case gl.BYTE:
+		value, scale = float64(int8(data[0])), math.MaxInt8
+	case gl.UNSIGNED_SHORT:
+		value, scale = float64(binary.LittleEndian.Uint16(data)), math.MaxUint16
+	case gl.SHORT:
+		value, scale = float64(int16(binary.LittleEndian.Uint16(data))), math.MaxInt16
+	case gl.UNSIGNED_INT:
+		value, scale = float64(binary.LittleEndian.Uint32(data)), math.MaxUint32
+	case gl.INT:
+		value, scale = float64(int32(binary.LittleEndian.Uint32(data))), math.MaxInt32
+	}
+	if p.normalized {
+		return float32(math.Max(value/scale, -1))
+	}
+
+	return float32(value)
+}
+
+// ReadPixels copies RGBA bytes out of the rasterizer in GL order, the
+// bottom row first, so callers flip them exactly like they would for a GPU.
+// Every framebuffer shares the one rasterizer in this backend.
+func (s *SoftwareBackend) ReadPixels(x, y, width, height int32, format, xtype uint32, pixels unsafe.Pointer) {
+	s.RecordingBackend.ReadPixels(x, y, width, height, format, xtype, pixels)
+	if format != gl.RGBA || xtype != gl.UNSIGNED_BYTE || pixels == nil {
+		return
+	}
+
+	img := s.Rasterizer.Image()
+	dst := unsafe.Slice((*byte)(pixels), int(width)*int(height)*4)
+	for row := 0; row < int(height); row++ {
+		srcY := s.Rasterizer.Height - 1 - (int(y) + row)
+		if srcY < 0 || srcY >= s.Rasterizer.Height {
+			continue
+		}
+		for col := 0; col < int(width); col++ {
+			srcX := int(x) + col
+			if srcX < 0 || srcX >= s.Rasterizer.Width {
+				continue
+			}
+			c := img.RGBAAt(srcX, srcY)
+			i := (row*int(width) + col) * 4
+			dst[i], dst[i+1], dst[i+2], dst[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package graphicsManager
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+// Compute enums, the 4.1 bindings stop short of them
+const (
+	ComputeShader           = 0x91B9
+	ShaderStorageBuffer     = 0x90D2
+	ShaderStorageBarrierBit = 0x2000
+	// VertexAttribArrayBarrierBit is the barrier to use when a compute
+	// shader wrote a buffer that is drawn from next
+	VertexAttribArrayBarrierBit = 0x1
+	AllBarrierBits              = 0xFFFFFFFF
+)
+
+// shaderStages is every stage SetShaderSource knows, in pipeline order
+// which is also the order newProgram attaches them in
+var shaderStages = []struct {
+	name  string
+	xtype uint32
+}{
+	{"vertex", gl.VERTEX_SHADER},
+	{"tessControl", gl.TESS_CONTROL_SHADER},
+	{"tessEvaluation", gl.TESS_EVALUATION_SHADER},
+	{"geometry", gl.GEOMETRY_SHADER},
+	{"fragment", gl.FRAGMENT_SHADER},
+	{"compute", ComputeShader},
+}
+
+// stageSource is one shader newProgram compiles and attaches
+type stageSource struct {
+	xtype  uint32
+	source string
+}
+
+// shaderStageName maps a shader type enum to the name SetShaderSource uses
+func shaderStageName(shaderType uint32) string {
+	for _, stage := range shaderStages {
+		if stage.xtype == shaderType {
+			return stage.name
+		}
+	}
+
+	return fmt.Sprintf("0x%X", shaderType)
+}
+
+// stageSource points at the field holding the source of a named stage
+func (glm *GLManager) stageSource(name string) *string {
+	switch name {
+	case "vertex":
+		return &glm.VS
+	case "tessControl":
+		return &glm.TCS
+	case "tessEvaluation":
+		return &glm.TES
+	case "geometry":
+		return &glm.GS
+	case "fragment":
+		return &glm.FS
+	case "compute":
+		return &glm.CS
+	}
+
+	return nil
+}
+
+// stageFile names a stage the way LoadShaders found it so logs point at
+// the file, stages set by hand go by the stage name
+func (glm *GLManager) stageFile(name string) string {
+	if glm.watch != nil {
+		if path, ok := glm.watch.paths[name]; ok {
+			return path
+		}
+	}
+
+	return name
+}
+
+// programStages collects the sources SetProgram links. Vertex and fragment
+// always go in so an empty one still fails to compile like it used to,
+// unless CS is the only thing set which makes a compute program.
+func (glm *GLManager) programStages() ([]stageSource, error) {
+	if glm.CS != "" {
+		for _, stage := range shaderStages[:len(shaderStages)-1] {
+			if *glm.stageSource(stage.name) != "" {
+				return nil, fmt.Errorf("%w: a compute shader can't be linked with the %s stage, use NewComputeProgram", ErrInvalidConfig, stage.name)
+			}
+		}
+		if err := glm.requireCompute(); err != nil {
+			return nil, err
+		}
+
+		return []stageSource{{ComputeShader, glm.CS}}, nil
+	}
+
+	var stages []stageSource
+	for _, stage := range shaderStages[:len(shaderStages)-1] {
+		source := *glm.stageSource(stage.name)
+		if source != "" || stage.xtype == gl.VERTEX_SHADER || stage.xtype == gl.FRAGMENT_SHADER {
+			stages = append(stages, stageSource{stage.xtype, source})
+		}
+	}
+
+	return stages, nil
+}
+
+var versionNumber = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+// GLVersion is the context version parsed from GL_VERSION, 0.0 when the
+// string makes no sense
+func (glm *GLManager) GLVersion() (major, minor int) {
+	m := versionNumber.FindStringSubmatch(glm.backend().GetString(gl.VERSION))
+	if m == nil {
+		return 0, 0
+	}
+	major, _ = strconv.Atoi(m[1])
+	minor, _ = strconv.Atoi(m[2])
+
+	return major, minor
+}
+
+// requireCompute fails on contexts older than 4.3, macOS stops at 4.1
+func (glm *GLManager) requireCompute() error {
+	major, minor := glm.GLVersion()
+	if major > 4 || major == 4 && minor >= 3 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: compute needs OpenGL 4.3, the context is %d.%d", ErrUnsupportedShaderType, major, minor)
+}
+
+// NewComputeProgram links a compute shader on its own so it can run next
+// to the program SetProgram made. The caller deletes it with Delete.
+func (glm *GLManager) NewComputeProgram(source string) (*ShaderProgram, error) {
+	if err := glm.requireCompute(); err != nil {
+		return nil, err
+	}
+
+	var processed *ProcessedSource
+	if pre := glm.preprocessor(); pre != nil {
+		p, err := pre.Process("compute", source)
+		if err != nil {
+			return nil, err
+		}
+		processed = p
+		source = p.Code
+	}
+
+	program, err := newProgram(glm.backend(), stageSource{ComputeShader, source})
+	if err != nil {
+		var compileErr *ShaderCompileError
+		if errors.As(err, &compileErr) {
+			compileErr.Processed = processed
+		}
+		return nil, err
+	}
+
+	return newShaderProgram(glm.backend, program), nil
+}
+
+// Dispatch runs a compute program over x*y*z work groups
+func (p *ShaderProgram) Dispatch(x, y, z uint32) error {
+	if err := p.Use(); err != nil {
+		return err
+	}
+
+	return p.backend().DispatchCompute(x, y, z)
+}
+
+// Delete releases the program, for programs from NewComputeProgram. The
+// one SetProgram made belongs to the manager.
+func (p *ShaderProgram) Delete() {
+	if p.ID != 0 {
+		p.backend().DeleteProgram(p.ID)
+		p.ID = 0
+	}
+}
+
+// MemoryBarrier makes writes from a dispatch visible to what comes next,
+// e.g. VertexAttribArrayBarrierBit before drawing a buffer it filled
+func (glm *GLManager) MemoryBarrier(barriers uint32) error {
+	return glm.backend().MemoryBarrier(barriers)
+}
+
+// BindStorageBuffer binds buffer to binding point index of the shader
+// storage blocks, layout(std430, binding = index) in the shader
+func (glm *GLManager) BindStorageBuffer(index, buffer uint32) {
+	glm.backend().BindBufferBase(ShaderStorageBuffer, index, buffer)
+}
+
+// SetPatchVertices is the number of vertices per patch when drawing with
+// gl.PATCHES through a tessellation program, GL defaults to 3
+func (glm *GLManager) SetPatchVertices(n int32) error {
+	if n < 1 {
+		return fmt.Errorf("%w: %d vertices per patch", ErrInvalidConfig, n)
+	}
+	glm.backend().PatchParameteri(gl.PATCH_VERTICES, n)
+
+	return nil
+}
+
+// stageNames is the list SetShaderSource accepts, for its error message
+func stageNames() string {
+	var names []string
+	for _, stage := range shaderStages {
+		names = append(names, fmt.Sprintf("%q", stage.name))
+	}
+
+	return strings.Join(names, ", ")
+}
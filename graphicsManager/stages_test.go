@@ -0,0 +1,86 @@
+package graphicsManager
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/stretchr/testify/assert"
+)
+
+func shaderTypes(calls []Call) []uint32 {
+	var types []uint32
+	for _, call := range calls {
+		types = append(types, call.Args[0].(uint32))
+	}
+
+	return types
+}
+
+func TestGLManager_ProgramStages(t *testing.T) {
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec, VS: "vertex", FS: "fragment"}
+	assert.NoError(t, manager.SetShaderSource("geometry", "geometry"))
+	assert.NoError(t, manager.SetShaderSource("control", "tessControl"))
+	assert.NoError(t, manager.SetShaderSource("evaluation", "tessEvaluation"))
+	assert.NoError(t, manager.SetProgram())
+
+	// Attached in pipeline order and all released after the link
+	assert.Equal(t, []uint32{gl.VERTEX_SHADER, gl.TESS_CONTROL_SHADER, gl.TESS_EVALUATION_SHADER, gl.GEOMETRY_SHADER, gl.FRAGMENT_SHADER},
+		shaderTypes(rec.CallsNamed("CreateShader")))
+	assert.Equal(t, 5, rec.Count("AttachShader"))
+	assert.Equal(t, 5, rec.Count("DeleteShader"))
+
+	assert.NoError(t, manager.SetPatchVertices(4))
+	assert.Equal(t, []any{uint32(gl.PATCH_VERTICES), int32(4)}, rec.CallsNamed("PatchParameteri")[0].Args)
+	assert.ErrorIs(t, manager.SetPatchVertices(0), ErrInvalidConfig)
+}
+
+func TestGLManager_StageCompileFailure(t *testing.T) {
+	rec := &RecordingBackend{FailShaderCompile: true}
+	manager := GLManager{Backend: rec, VS: "vertex", GS: "geometry", FS: "fragment"}
+
+	err := manager.SetProgram()
+	var compileErr *ShaderCompileError
+	if assert.ErrorAs(t, err, &compileErr) {
+		assert.Equal(t, "vertex", compileErr.Stage)
+	}
+	assert.Equal(t, rec.Count("CreateShader"), rec.Count("DeleteShader"))
+	assert.Zero(t, rec.Count("CreateProgram"))
+}
+
+func TestGLManager_Compute(t *testing.T) {
+	rec := &RecordingBackend{}
+	manager := GLManager{Backend: rec}
+
+	// The recording backend says 4.1 like a Mac would
+	_, err := manager.NewComputeProgram("void main() {}")
+	assert.ErrorIs(t, err, ErrUnsupportedShaderType)
+	manager.CS = "void main() {}"
+	assert.ErrorIs(t, manager.SetProgram(), ErrUnsupportedShaderType)
+
+	rec.Version = "4.5 (Core Profile) Mesa 22.3.6"
+	major, minor := manager.GLVersion()
+	assert.Equal(t, []int{4, 5}, []int{major, minor})
+
+	assert.NoError(t, manager.SetProgram())
+	assert.Equal(t, []uint32{ComputeShader}, shaderTypes(rec.CallsNamed("CreateShader")))
+
+	// Mixing compute with graphics stages is a mistake
+	manager.VS = "void main() {}"
+	assert.ErrorIs(t, manager.SetProgram(), ErrInvalidConfig)
+
+	compute, err := manager.NewComputeProgram("void main() {}")
+	assert.NoError(t, err)
+	assert.NoError(t, compute.Dispatch(8, 4, 1))
+	assert.Equal(t, compute.ID, rec.CurrentProgram())
+	assert.Equal(t, []any{uint32(8), uint32(4), uint32(1)}, rec.CallsNamed("DispatchCompute")[0].Args)
+
+	manager.BindStorageBuffer(0, 7)
+	assert.NoError(t, manager.MemoryBarrier(VertexAttribArrayBarrierBit))
+	assert.Equal(t, []any{uint32(ShaderStorageBuffer), uint32(0), uint32(7)}, rec.CallsNamed("BindBufferBase")[0].Args)
+	assert.Equal(t, 1, rec.Count("MemoryBarrier"))
+
+	compute.Delete()
+	assert.Zero(t, compute.ID)
+	assert.ErrorIs(t, compute.Dispatch(1, 1, 1), ErrNoProgram)
+}
@@ -0,0 +1,187 @@
+package graphicsManager
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Stream names VertexStorage knows by heart, anything else is a custom stream
+const (
+	StreamPosition = "position"
+	StreamColor    = "color"
+	StreamNormal   = "normal"
+	StreamUV       = "uv"
+)
+
+// streamAliases keeps the selections PutSlice has always accepted working
+var streamAliases = map[string]string{
+	"object": StreamPosition,
+}
+
+// VertexStorage keeps any number of named per vertex streams, position,
+// color, normal, uv or whatever a shader wants, in the order they were
+// first added. It implements VerticeStorer, PutVal appends to the stream
+// last picked with Select or PutSlice, position until then. The zero value
+// is ready to use.
+type VertexStorage struct {
+	names    []string
+	streams  map[string][]mgl32.Vec4
+	selected string
+
+	// Changes since the last upload, see dirty.go
+	dirty   map[string][]DirtyRange
+	resized map[string]bool
+}
+
+var _ VerticeStorer = (*VertexStorage)(nil)
+
+func NewVertexStorage() *VertexStorage {
+	return &VertexStorage{}
+}
+
+func streamName(name string) string {
+	if alias, ok := streamAliases[name]; ok {
+		return alias
+	}
+
+	return name
+}
+
+// Set replaces a whole stream, creating it if it's new. When the length
+// stays the same only the vertices that differ are marked dirty.
+func (s *VertexStorage) Set(name string, data []mgl32.Vec4) error {
+	name = streamName(name)
+	if name == "" {
+		return fmt.Errorf("%w: stream needs a name", ErrInvalidStorageSelection)
+	}
+	if s.streams == nil {
+		s.streams = map[string][]mgl32.Vec4{}
+	}
+	old, ok := s.streams[name]
+	if !ok {
+		s.names = append(s.names, name)
+	}
+	s.markChanged(name, old, data)
+	s.streams[name] = data
+
+	return nil
+}
+
+// Stream is the data of one stream, nil when there is no such stream
+func (s *VertexStorage) Stream(name string) []mgl32.Vec4 {
+	return s.streams[streamName(name)]
+}
+
+// Has reports whether a stream exists, even an empty one
+func (s *VertexStorage) Has(name string) bool {
+	_, ok := s.streams[streamName(name)]
+	return ok
+}
+
+// Names lists the streams in the order they were added
+func (s *VertexStorage) Names() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Streams is every stream by name, the slices are shared with the storage
+func (s *VertexStorage) Streams() map[string][]mgl32.Vec4 {
+	result := make(map[string][]mgl32.Vec4, len(s.streams))
+	for name, data := range s.streams {
+		result[name] = data
+	}
+
+	return result
+}
+
+func (s *VertexStorage) Remove(name string) {
+	name = streamName(name)
+	if _, ok := s.streams[name]; !ok {
+		return
+	}
+	delete(s.streams, name)
+	delete(s.dirty, name)
+	s.markResized(name)
+	for i, n := range s.names {
+		if n == name {
+			s.names = append(s.names[:i], s.names[i+1:]...)
+			break
+		}
+	}
+}
+
+// Clear drops every stream
+func (s *VertexStorage) Clear() {
+	for _, name := range s.names {
+		s.markResized(name)
+	}
+	s.names, s.streams, s.selected, s.dirty = nil, nil, "", nil
+}
+
+// Select picks the stream PutVal appends to
+func (s *VertexStorage) Select(name string) {
+	s.selected = streamName(name)
+}
+
+// Len is the vertex count, the length of the first stream
+func (s *VertexStorage) Len() int {
+	if len(s.names) == 0 {
+		return 0
+	}
+
+	return len(s.streams[s.names[0]])
+}
+
+// Validate checks that every stream holds the same number of vertices
+func (s *VertexStorage) Validate() error {
+	for _, name := range s.names {
+		if len(s.streams[name]) != s.Len() {
+			return fmt.Errorf("%w: %s has %d vertices, %s has %d", ErrStreamLength, name, len(s.streams[name]), s.names[0], s.Len())
+		}
+	}
+
+	return nil
+}
+
+// GetAll returns every stream in order, with an error when their lengths
+// disagree since no draw call could use them together
+func (s *VertexStorage) GetAll() ([][]mgl32.Vec4, error) {
+	all := make([][]mgl32.Vec4, 0, len(s.names))
+	for _, name := range s.names {
+		all = append(all, s.streams[name])
+	}
+
+	return all, s.Validate()
+}
+
+// PutSlice appends to a stream and selects it for PutVal
+func (s *VertexStorage) PutSlice(slice []mgl32.Vec4, selection string) error {
+	name := streamName(selection)
+	if name == "" {
+		return fmt.Errorf("%w: stream needs a name", ErrInvalidStorageSelection)
+	}
+	if err := s.appendTo(name, slice...); err != nil {
+		return err
+	}
+	s.selected = name
+
+	return nil
+}
+
+// PutVal appends one value to the selected stream
+func (s *VertexStorage) PutVal(value mgl32.Vec4) error {
+	name := s.selected
+	if name == "" {
+		name = StreamPosition
+	}
+
+	return s.appendTo(name, value)
+}
+
+// appendTo grows a stream through Set. The stream may be a slice the
+// caller handed to Set, it's clipped so append copies instead of writing
+// into the caller's spare capacity.
+func (s *VertexStorage) appendTo(name string, values ...mgl32.Vec4) error {
+	stream := s.Stream(name)
+	return s.Set(name, append(stream[:len(stream):len(stream)], values...))
+}
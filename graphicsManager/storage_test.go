@@ -0,0 +1,71 @@
+package graphicsManager
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVertexStorage(t *testing.T) {
+	var storage VertexStorage
+
+	assert.NoError(t, storage.Set(StreamPosition, []mgl32.Vec4{{0, 0, 0, 1}, {1, 0, 0, 1}}))
+	assert.NoError(t, storage.PutSlice([]mgl32.Vec4{{0, 0, 1, 0}}, StreamNormal))
+	storage.PutVal(mgl32.Vec4{0, 0, 1, 0})
+	assert.NoError(t, storage.Set("aTangent", []mgl32.Vec4{{1, 0, 0, 0}, {1, 0, 0, 0}}))
+
+	assert.Equal(t, []string{StreamPosition, StreamNormal, "aTangent"}, storage.Names())
+	assert.Equal(t, 2, storage.Len())
+	assert.Len(t, storage.Stream(StreamNormal), 2)
+
+	all, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	// One uv short of the rest
+	assert.NoError(t, storage.Set(StreamUV, []mgl32.Vec4{{0, 0, 0, 0}}))
+	_, err = storage.GetAll()
+	assert.ErrorIs(t, err, ErrStreamLength)
+
+	storage.Remove(StreamUV)
+	assert.NoError(t, storage.Validate())
+	assert.False(t, storage.Has(StreamUV))
+
+	// Without a selection PutVal goes to position
+	storage.Clear()
+	storage.PutVal(mgl32.Vec4{1, 2, 3, 1})
+	assert.Equal(t, []mgl32.Vec4{{1, 2, 3, 1}}, storage.Stream(StreamPosition))
+}
+
+func TestVertexStorage_AppendCopies(t *testing.T) {
+	var storage VertexStorage
+
+	// Spare capacity in the caller's slice must not be written into
+	backing := make([]mgl32.Vec4, 1, 4)
+	backing[0] = mgl32.Vec4{1, 0, 0, 1}
+	assert.NoError(t, storage.Set(StreamPosition, backing))
+	assert.NoError(t, storage.PutSlice([]mgl32.Vec4{{2, 0, 0, 1}}, StreamPosition))
+	assert.NoError(t, storage.PutVal(mgl32.Vec4{3, 0, 0, 1}))
+
+	assert.Equal(t, []mgl32.Vec4{{1, 0, 0, 1}, {2, 0, 0, 1}, {3, 0, 0, 1}}, storage.Stream(StreamPosition))
+	assert.Equal(t, []mgl32.Vec4{{0, 0, 0, 0}, {0, 0, 0, 0}}, backing[1:3])
+}
+
+func TestGLManager_BindLayoutFromStorage(t *testing.T) {
+	rec := NewRecordingBackend()
+	manager := GLManager{Backend: rec, Program: 1}
+
+	storage := manager.Storage()
+	storage.Set(StreamPosition, []mgl32.Vec4{{0, 0, 0, 1}, {1, 0, 0, 1}, {0, 1, 0, 1}})
+	storage.Set(StreamUV, []mgl32.Vec4{{0, 0, 0, 0}, {1, 0, 0, 0}, {0, 1, 0, 0}})
+	manager.SetVertexLayout(NewVertexLayout(false,
+		VertexAttribute{Name: "aPosition", Stream: StreamPosition, Components: 3, Type: gl.FLOAT},
+		VertexAttribute{Name: "aTexCoord", Stream: StreamUV, Components: 2, Type: gl.FLOAT},
+	))
+
+	assert.NoError(t, manager.BindLayout())
+	assert.Equal(t, 3, manager.VertexCount())
+	assert.Len(t, rec.BufferContents[manager.VBOs()[1]], 3*2*4)
+}
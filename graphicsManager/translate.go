@@ -0,0 +1,268 @@
+package graphicsManager
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GLSLDialect is a GLSL flavor TranslateShader can write
+type GLSLDialect int
+
+const (
+	// GLSLCore410 is what the native demos compile, "#version 410 core"
+	GLSLCore410 GLSLDialect = iota
+	// GLSLES300 is WebGL2, "#version 300 es", what the WebGL/ pages use
+	GLSLES300
+)
+
+// String is the #version argument
+func (d GLSLDialect) String() string {
+	if d == GLSLES300 {
+		return "300 es"
+	}
+
+	return "410 core"
+}
+
+// sourceEdit replaces source[start:end] with text
+type sourceEdit struct {
+	start, end int
+	text       string
+}
+
+// The words TranslateShader looks at outside of comments and directives
+var (
+	translateWord = regexp.MustCompile(`\b(attribute|varying|precision|highp|mediump|lowp|texture2D|textureCube|gl_FragColor)\b`)
+	// desktopOnly has no ES 3.00 version to fall back on
+	desktopOnly = regexp.MustCompile(`\b(double|[di]?dvec[234]|dmat[234](x[234])?|noperspective|subroutine|gl_ClipDistance|gl_PrimitiveID)\b`)
+	// ES only takes locations on vertex inputs and fragment outputs
+	locationLayout = regexp.MustCompile(`layout\s*\(\s*location\s*=\s*\d+\s*\)\s*`)
+	defaultFloat   = regexp.MustCompile(`\bprecision\s+(highp|mediump|lowp)\s+float\s*;`)
+)
+
+// fragColor replaces gl_FragColor, which neither dialect has any more
+const fragColor = "fragColor"
+
+// TranslateShader rewrites one stage for the other dialect so the same
+// shader works in the browser and in the native demos:
+//
+//   - #version is replaced, or added when there is none
+//   - attribute and varying become in and out, gl_FragColor becomes a
+//     declared output and texture2D/textureCube become texture, older
+//     shaders are brought up to date either way
+//   - going to 410 precision statements and qualifiers are dropped, going
+//     to ES the fragment shader gets a default float precision
+//   - going to ES layout(location) is dropped from varyings, doubles and
+//     other desktop only features are an error
+//
+// Line numbers are kept where nothing has to be added, so compiler
+// messages still point at the right line of the original.
+func TranslateShader(stage, source string, to GLSLDialect) (string, error) {
+	source = strings.TrimRight(source, "\x00")
+	if to == GLSLES300 && stage != "vertex" && stage != "fragment" {
+		return "", fmt.Errorf("%w: WebGL2 has no %s shader", ErrUnsupportedShaderType, stage)
+	}
+	// The comments are blanked in a copy with the same offsets, so nothing
+	// in them is touched
+	code, err := stripComments(source)
+	if err != nil {
+		return "", err
+	}
+
+	var edits []sourceEdit
+	versionEnd := -1
+	// Where added declarations go, after #version and any #extension
+	header := 0
+	precision := false
+	offset := 0
+	for n, line := range strings.SplitAfter(code, "\n") {
+		start := offset
+		offset += len(line)
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			if _, ok := directive(line, "version"); ok && versionEnd < 0 {
+				edits = append(edits, sourceEdit{start, start + len(strings.TrimRight(line, "\r\n")), line[:indent(line)] + "#version " + to.String()})
+				versionEnd, header = offset, offset
+			} else if _, ok := directive(line, "extension"); ok {
+				header = offset
+			}
+			continue
+		}
+
+		if to == GLSLES300 {
+			if m := desktopOnly.FindString(line); m != "" {
+				return "", fmt.Errorf("%w: line %d: %s isn't in GLSL ES 3.00", ErrInvalidShader, n+1, m)
+			}
+		}
+		if to == GLSLES300 && defaultFloat.MatchString(line) {
+			precision = true
+		}
+
+		for _, m := range translateWord.FindAllStringIndex(line, -1) {
+			edit, err := translateEdit(stage, to, code, start+m[0], start+m[1])
+			if err != nil {
+				return "", fmt.Errorf("line %d: %w", n+1, err)
+			}
+			if edit != nil {
+				edits = append(edits, *edit)
+			}
+		}
+	}
+
+	if to == GLSLES300 {
+		layoutEdits, err := varyingLocations(stage, code)
+		if err != nil {
+			return "", err
+		}
+		edits = append(edits, layoutEdits...)
+	}
+
+	var added []string
+	if versionEnd < 0 {
+		added = append(added, "#version "+to.String())
+	}
+	if to == GLSLES300 && stage == "fragment" && !precision {
+		added = append(added, "precision highp float;")
+	}
+	if stage == "fragment" && translateWordUsed(code, "gl_FragColor") {
+		// The source's own precision statement may come after this
+		if to == GLSLES300 {
+			added = append(added, "out highp vec4 "+fragColor+";")
+		} else {
+			added = append(added, "out vec4 "+fragColor+";")
+		}
+	}
+	if len(added) > 0 {
+		text := strings.Join(added, "\n") + "\n"
+		if header == versionEnd && versionEnd > 0 && !strings.HasSuffix(code[:versionEnd], "\n") {
+			// #version was the last line
+			text = "\n" + text
+		}
+		edits = append(edits, sourceEdit{header, header, text})
+	}
+
+	return applyEdits(source, edits), nil
+}
+
+// translateEdit is what one translateWord match at code[start:end] turns into
+func translateEdit(stage string, to GLSLDialect, code string, start, end int) (*sourceEdit, error) {
+	switch word := code[start:end]; word {
+	case "attribute":
+		if stage != "vertex" {
+			return nil, fmt.Errorf("%w: attribute in a %s shader", ErrInvalidShader, stage)
+		}
+		return &sourceEdit{start, end, "in"}, nil
+	case "varying":
+		switch stage {
+		case "vertex":
+			return &sourceEdit{start, end, "out"}, nil
+		case "fragment":
+			return &sourceEdit{start, end, "in"}, nil
+		}
+		return nil, fmt.Errorf("%w: varying in a %s shader", ErrInvalidShader, stage)
+	case "texture2D", "textureCube":
+		return &sourceEdit{start, end, "texture"}, nil
+	case "gl_FragColor":
+		return &sourceEdit{start, end, fragColor}, nil
+	case "precision":
+		if to != GLSLCore410 {
+			return nil, nil
+		}
+		// The whole statement goes, 410 only parses it for ES compatibility
+		semicolon := strings.IndexByte(code[end:], ';')
+		if semicolon < 0 {
+			return nil, fmt.Errorf("%w: unterminated precision statement", ErrInvalidShader)
+		}
+		return &sourceEdit{start, end + semicolon + 1, ""}, nil
+	default:
+		// highp, mediump and lowp in declarations
+		if to != GLSLCore410 || precisionStatement(code, start) {
+			return nil, nil
+		}
+		for end < len(code) && (code[end] == ' ' || code[end] == '\t') {
+			end++
+		}
+		return &sourceEdit{start, end, ""}, nil
+	}
+}
+
+// precisionStatement reports whether the qualifier at code[start] is part
+// of a precision statement, which is removed as a whole
+func precisionStatement(code string, start int) bool {
+	statement := strings.LastIndexAny(code[:start], ";{}\n")
+
+	return strings.HasPrefix(strings.TrimSpace(code[statement+1:start]), "precision")
+}
+
+func translateWordUsed(code, word string) bool {
+	for _, m := range translateWord.FindAllString(code, -1) {
+		if m == word {
+			return true
+		}
+	}
+
+	return false
+}
+
+// varyingLocations drops layout(location) from vertex outputs and fragment
+// inputs, ES matches those by name only
+func varyingLocations(stage, code string) ([]sourceEdit, error) {
+	iface, err := ParseGLSLInterface(stage, code)
+	if err != nil {
+		return nil, err
+	}
+	varyings := iface.Outputs
+	if stage == "fragment" {
+		varyings = iface.Inputs
+	}
+
+	lines := strings.SplitAfter(code, "\n")
+	var edits []sourceEdit
+	done := map[int]bool{}
+	for _, d := range varyings {
+		if d.Location < 0 || done[d.Line] {
+			continue
+		}
+		done[d.Line] = true
+		start := 0
+		for _, line := range lines[:d.Line-1] {
+			start += len(line)
+		}
+		// The layout is on the same line as the name in everything but
+		// the most creative formatting
+		if m := locationLayout.FindStringIndex(lines[d.Line-1]); m != nil {
+			edits = append(edits, sourceEdit{start + m[0], start + m[1], ""})
+		}
+	}
+
+	return edits, nil
+}
+
+func applyEdits(source string, edits []sourceEdit) string {
+	// Insertions go before whatever replaces the text after them
+	sort.SliceStable(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].start == edits[i].end && edits[j].start != edits[j].end
+	})
+	var b strings.Builder
+	at := 0
+	for _, e := range edits {
+		if e.start < at {
+			// Already removed with a precision statement
+			continue
+		}
+		b.WriteString(source[at:e.start])
+		b.WriteString(e.text)
+		at = e.end
+	}
+	b.WriteString(source[at:])
+
+	return b.String()
+}
+
+func indent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
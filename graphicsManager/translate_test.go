@@ -0,0 +1,102 @@
+package graphicsManager
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateShader_ToCore(t *testing.T) {
+	es := `#version 300 es
+precision mediump float; // attribute and varying in a comment stay
+uniform highp sampler2D uTex;
+in  mediump vec2 vUV;
+out vec4 fColor;
+void main() { fColor = texture(uTex, vUV); }
+`
+	out, err := TranslateShader("fragment", es, GLSLCore410)
+	assert.NoError(t, err)
+	assert.Equal(t, `#version 410 core
+ // attribute and varying in a comment stay
+uniform sampler2D uTex;
+in  vec2 vUV;
+out vec4 fColor;
+void main() { fColor = texture(uTex, vUV); }
+`, out)
+
+	// Legacy ES 100 forms come up to date, the #version is added
+	out, err = TranslateShader("fragment", "varying vec2 vUV;\nvoid main() { gl_FragColor = texture2D(uTex, vUV); }\n", GLSLCore410)
+	assert.NoError(t, err)
+	assert.Equal(t, "#version 410 core\nout vec4 fragColor;\nin vec2 vUV;\nvoid main() { fragColor = texture(uTex, vUV); }\n", out)
+	out, err = TranslateShader("vertex", "\t#version 100\n\tattribute vec4 aPosition;\n\tvarying vec4 vColor;\n", GLSLCore410)
+	assert.NoError(t, err)
+	assert.Equal(t, "\t#version 410 core\n\tin vec4 aPosition;\n\tout vec4 vColor;\n", out)
+
+	_, err = TranslateShader("fragment", "attribute vec4 a;\n", GLSLCore410)
+	assert.ErrorIs(t, err, ErrInvalidShader)
+}
+
+func TestTranslateShader_ToES(t *testing.T) {
+	core := "#version 410\n#extension GL_ARB_separate_shader_objects : enable\nlayout(location = 0) in vec4 vColor;\nlayout(location = 0) out vec4 fColor;\nvoid main() { fColor = vColor; }\n"
+	out, err := TranslateShader("fragment", core, GLSLES300)
+	assert.NoError(t, err)
+	assert.Equal(t, "#version 300 es\n#extension GL_ARB_separate_shader_objects : enable\nprecision highp float;\nin vec4 vColor;\nlayout(location = 0) out vec4 fColor;\nvoid main() { fColor = vColor; }\n", out)
+
+	// A default precision that's already there is kept
+	out, err = TranslateShader("fragment", "#version 410\nprecision mediump float;\nout vec4 c;\n", GLSLES300)
+	assert.NoError(t, err)
+	assert.Equal(t, "#version 300 es\nprecision mediump float;\nout vec4 c;\n", out)
+
+	_, err = TranslateShader("geometry", "void main() {}", GLSLES300)
+	assert.ErrorIs(t, err, ErrUnsupportedShaderType)
+	_, err = TranslateShader("vertex", "#version 410\nuniform dvec3 uOrigin;\n", GLSLES300)
+	assert.ErrorIs(t, err, ErrInvalidShader)
+}
+
+// webGLShaders pulls the shader scripts out of one of the WebGL/ pages
+func webGLShaders(t *testing.T, page string) map[string]string {
+	t.Helper()
+	html, err := os.ReadFile("../WebGL/" + page + ".html")
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	scripts := regexp.MustCompile(`(?s)<script id="[^"]*" type="x-shader/x-(vertex|fragment)">(.*?)</script>`)
+	sources := map[string]string{}
+	for _, m := range scripts.FindAllStringSubmatch(string(html), -1) {
+		sources[m[1]] = m[2]
+	}
+	assert.Len(t, sources, 2)
+
+	return sources
+}
+
+// The browser shaders translate to 410 and back and the stages still
+// line up both ways
+func TestTranslateShader_WebGLDemos(t *testing.T) {
+	for _, page := range []string{"cube", "cubeq", "interactiveView", "wireSphere", "paintersAlgo", "sg"} {
+		t.Run(page, func(t *testing.T) {
+			core, es := map[string]string{}, map[string]string{}
+			for stage, source := range webGLShaders(t, page) {
+				var err error
+				core[stage], err = TranslateShader(stage, source, GLSLCore410)
+				assert.NoError(t, err)
+				es[stage], err = TranslateShader(stage, core[stage], GLSLES300)
+				assert.NoError(t, err)
+			}
+			for _, sources := range []map[string]string{core, es} {
+				diagnostics, err := CheckShaderInterfaces(nil, sources)
+				assert.NoError(t, err)
+				for _, d := range diagnostics {
+					if d.Severity == SeverityError {
+						t.Error(d.Format())
+					}
+				}
+			}
+			assert.Contains(t, core["vertex"], "#version 410 core")
+			assert.NotContains(t, core["fragment"], "precision")
+			assert.Contains(t, es["fragment"], "precision highp float;")
+		})
+	}
+}
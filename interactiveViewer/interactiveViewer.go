@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math"
 	"runtime"
@@ -38,19 +39,8 @@ const (
 
 	uniform vec3 uTheta;
 
-	// quaternion multiplier
-
-	vec4 multq(vec4 a, vec4 b)
-	{
-		return (vec4(a.x*b.x - dot(a.yzw, b.yzw), a.x*b.yzw+b.x*a.yzw+cross(b.yzw, a.yzw)));
-	}
-
-	// inverse quaternion
-
-	vec4 invq(vec4 a)
-	{
-		return (vec4(a.x, -a.yzw)/dot(a,a));
-	}
+	// multq and invq come from the graphicsManager shader library
+	#include "quaternion.glsl"
 
 	uniform mat4 uModelViewMatrix;
 	uniform mat4 uProjectionMatrix;
@@ -77,7 +67,7 @@ const (
 		gl_Position.z = -gl_Position.z; // inverse/reflect
 
 	}
-		` + "\x00"
+		`
 
 	FRAGMENTSHADERSOURCE = `
 	#version 410
@@ -86,7 +76,7 @@ const (
 	void main() {
 		fColor = vColor;
 	}
-		` + "\x00"
+		`
 )
 
 const (
@@ -149,38 +139,26 @@ var (
 	lastMouseX, lastMouseY float64
 )
 
+var glDebug = flag.Bool("gldebug", false, "report GL errors and driver warnings as they happen")
+
 func main() {
 	runtime.LockOSThread()
+	flag.Parse()
 
-	if err := glfw.Init(); err != nil {
-		fmt.Println("glfw.Init() failed:", err)
-		return
+	opts := []graphicsManager.Option{graphicsManager.WithTitle("Rotating Cube")}
+	if *glDebug {
+		opts = append(opts, graphicsManager.WithDebug(graphicsManager.DebugSeverityLow))
 	}
-	defer glfw.Terminate()
-
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	window, err := glfw.CreateWindow(800, 600, "Rotating Cube", nil, nil)
+	glm, err := graphicsManager.NewGLManager(opts...)
 	if err != nil {
-		fmt.Println("glfw.CreateWindow() failed:", err)
+		fmt.Println("NewGLManager() failed:", err)
 		return
 	}
+	defer glm.Terminate()
 
-	window.MakeContextCurrent()
-
-	if err := gl.Init(); err != nil {
-		fmt.Println("gl.Init() failed:", err)
-
-	}
-
-	glm := graphicsManager.GLManager{
-		Window: window,
-		VS:     VERTEXSHADERSOURCE,
-		FS:     FRAGMENTSHADERSOURCE,
-	}
+	glm.VS = VERTEXSHADERSOURCE
+	glm.FS = FRAGMENTSHADERSOURCE
+	glm.Preprocessor = graphicsManager.NewPreprocessor(graphicsManager.ShaderLibrary)
 
 	go func() {
 		runNucularGUI()
@@ -189,27 +167,30 @@ func main() {
 	glm.NewVec4Storage()
 	glm.NewFloat32Storage()
 
-	glm.Window.SetMouseButtonCallback(mouseEventListener)
+	glm.GetWindow().SetMouseButtonCallback(mouseEventListener)
 	// Set clear color
 	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
 	gl.Enable(gl.DEPTH_TEST)
 	gl.Viewport(0, 0, 800, 600)
-	if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-		fmt.Println("OpenGL error after drawing colors:", errCode)
-		return
-	}
 
 	// Once the shader sources are configured we can create a program
 
-	glm.SetProgram()
+	if err := glm.SetProgram(); err != nil {
+		fmt.Println("SetProgram() failed:", graphicsManager.FormatShaderError(err))
+		return
+	}
 
-	// Maybe here we send attribute data
-	shaderLocName := gl.Str("uTheta" + "\x00")
-	// Go strings need to be converted into null-terminated C strings.
-	thetaLoc := gl.GetUniformLocation(glm.GetProgram(), shaderLocName)
+	// Uniform locations are looked up once and cached by the shader
+	shader := glm.Shader()
+	for _, u := range glm.ActiveUniforms() {
+		fmt.Println("Active uniform:", u)
+	}
 
 	// You can send floats, scalars, vectors, matrices to uniform
-	glm.BindProgram()
+	if err := glm.BindProgram(); err != nil {
+		fmt.Println("BindProgram() failed:", err)
+		return
+	}
 
 	glm.SetGeoVertices(v3DCube)
 	glm.SetColorVertices(cubeColors)
@@ -217,83 +198,46 @@ func main() {
 	// Create the buffer object that holds the positions, normals, colors and texture coordinates.
 	// The SINGLE vbo can store this data on the GPU for each unique object
 	// Multiple VBO's can be set up
-	// TODO: Create a buffer pool and pointers to the last, next, and current buffers for use
+	// Geometry that changes every frame goes through glm.BufferPool() instead
 	colorCube(glm)
 
-	// Find shader variable name
-	geoCname := gl.Str("aPosition" + "\x00")
-	positionLoc := gl.GetAttribLocation(glm.GetProgram(), geoCname)
-
-	colorCname := gl.Str("aColor" + "\x00")
-	colorLoc := gl.GetAttribLocation(glm.GetProgram(), colorCname)
-
-	modelViewCname := gl.Str("uModelViewMatrix" + "\x00")
-	modelViewMatLoc := gl.GetUniformLocation(glm.GetProgram(), modelViewCname)
-
-	projMatCname := gl.Str("uProjectionMatrix" + "\x00")
-	projMatLoc := gl.GetUniformLocation(glm.GetProgram(), projMatCname)
-	// Bind VAO.
-	// Bind VBO.
-	// Set vertex attribute pointers.
-	// Unbind VBO
-	// Unbind VAO.
-	geoVBO := makeVbo()
-	cVBO := makeVbo()
-	VAO := makeVao()
-
-	// Create and bind a single VAO
-	gl.BindVertexArray(VAO)
-
-	// Setup for position data
-	gl.BindBuffer(gl.ARRAY_BUFFER, geoVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(Positions)*4*4), gl.Ptr(&Positions[0]), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(uint32(positionLoc))
-	gl.VertexAttribPointer(uint32(positionLoc), 4, gl.FLOAT, false, 0, nil)
-
-	// Setup for color data
-	gl.BindBuffer(gl.ARRAY_BUFFER, cVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(Colors)*4*4), gl.Ptr(&Colors[0]), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(uint32(colorLoc))
-	gl.VertexAttribPointer(uint32(colorLoc), 4, gl.FLOAT, false, 0, nil)
-
-	// Unbind VBO and VAO
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	gl.BindVertexArray(0)
+	// Swap the 8 corners for the expanded face vertices, the layout finds
+	// aPosition and aColor in the program and sets up the VAO for us
+	glm.SetGeoVertices(graphicsManager.Float32ToVec4(Positions))
+	glm.SetColorVertices(graphicsManager.Float32ToVec4(Colors))
+	glm.SetVertexLayout(graphicsManager.NewVertexLayout(true,
+		graphicsManager.Float4(graphicsManager.PositionAttribute),
+		graphicsManager.Float4(graphicsManager.ColorAttribute),
+	))
+	if err := glm.BindLayout(); err != nil {
+		fmt.Println("BindLayout() failed:", err)
+		return
+	}
+	if report := glm.LayoutReport(); !report.Clean() {
+		fmt.Println("Warning:", report)
+	}
 	glm.RenderCall = func() {
-		if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-			fmt.Println("OpenGL error before rendering:", errCode)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		modelViewMatrix, projectionMatrix := viewMatrices()
+
+		if err := shader.SetMat4("uModelViewMatrix", modelViewMatrix); err != nil {
+			fmt.Println("SetMat4() failed:", err)
 			return
 		}
-
-		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
-		// Create polar coordinates for the eye, when looking at the origin of object coordinates
-		eye := mgl32.Vec4{radius * float32(math.Sin(float64(theta[0]))) * float32(math.Cos(float64(phi))),
-			radius * float32(math.Sin(float64(theta[0]))) * float32(math.Sin(float64(phi))),
-			radius * float32(math.Cos(float64(theta[0]))), 1.0}
-		// Create the model view matrix using the u v n properties, looking at the origin
-		modelViewMatrix := mgl32.LookAt(eye.X(), eye.Y(), eye.Z(), at.X(), at.Y(), at.Z(), up.X(), up.Y(), up.Z())
-
-		gl.UniformMatrix4fv(modelViewMatLoc, 1, false, &modelViewMatrix[0])
-		// An orthographic projection
-		projectionMatrix := ortho(left, right, bottom, top, near, far)
 		// Give the information to the Shader
-		gl.UniformMatrix4fv(projMatLoc, 1, false, &projectionMatrix[0])
+		if err := shader.SetMat4("uProjectionMatrix", projectionMatrix); err != nil {
+			fmt.Println("SetMat4() failed:", err)
+			return
+		}
 		// Rotating cube render
-		updateRotation(glm.Window)
+		updateRotation(glm.GetWindow())
 
-		var t []float32
-		t = append(t, float32(theta[0]))
-		// Update the uniform
-		gl.Uniform3fv(thetaLoc, 1, &t[0])
+		// Update the uniform, the shader doesn't use uTheta for gl_Position
+		// yet so the compiler drops it and SetVec3 only warns once
+		_ = shader.SetVec3("uTheta", mgl32.Vec3{float32(theta[0]), float32(theta[1]), float32(theta[2])})
 
 		// Bind the single VAO
-		gl.BindVertexArray(VAO)
-		gl.DrawArrays(gl.TRIANGLES, 0, numPositions)
-
-		if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-			fmt.Println("OpenGL error after drawing:", errCode)
-			return
-		}
+		glm.DrawArrays(gl.TRIANGLES)
 	}
 
 	glm.RunLoop(60)
@@ -321,7 +265,7 @@ func updatefn(w *nucular.Window) {
 
 }
 
-func colorCube(glm graphicsManager.GLManager) {
+func colorCube(glm *graphicsManager.GLManager) {
 	Quad(1, 0, 3, 2, glm)
 	Quad(2, 3, 7, 6, glm)
 	Quad(3, 0, 4, 7, glm)
@@ -331,23 +275,7 @@ func colorCube(glm graphicsManager.GLManager) {
 
 }
 
-func makeVbo() uint32 {
-
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-
-	return vbo
-}
-
-func makeVao() uint32 {
-
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-
-	return vao
-}
-
-func Quad(a, b, c, d int, glm graphicsManager.GLManager) {
+func Quad(a, b, c, d int, glm *graphicsManager.GLManager) {
 
 	vertices := glm.Vec4Storage().ObjectVertices
 
@@ -356,21 +284,14 @@ func Quad(a, b, c, d int, glm graphicsManager.GLManager) {
 	var indices = []int{a, b, c, a, c, d}
 
 	for i := 0; i < len(indices); i++ {
-		fmt.Println(i)
-		Positions = append(Positions, vecToFloat32(vertices[indices[i]])...)
+		// A Vec4 is already 4 floats, slicing it appends without a temporary
+		Positions = append(Positions, vertices[indices[i]][:]...)
 
-		Colors = append(Colors, vecToFloat32(vertexColors[a])...)
+		Colors = append(Colors, vertexColors[a][:]...)
 	}
 
 }
 
-func vecToFloat32(vec mgl32.Vec4) []float32 {
-	float32Array := make([]float32, 0, 4)
-
-	float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z(), vec.W())
-	return float32Array
-}
-
 func mouseEventListener(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
 	if button == glfw.MouseButtonLeft {
 		if action == glfw.Press {
@@ -401,6 +322,20 @@ func updateRotation(window *glfw.Window) {
 	}
 }
 
+// viewMatrices builds the model view and projection matrices from the current slider values
+func viewMatrices() (modelView, projection mgl32.Mat4) {
+	// Create polar coordinates for the eye, when looking at the origin of object coordinates
+	eye := mgl32.Vec4{radius * float32(math.Sin(float64(theta[0]))) * float32(math.Cos(float64(phi))),
+		radius * float32(math.Sin(float64(theta[0]))) * float32(math.Sin(float64(phi))),
+		radius * float32(math.Cos(float64(theta[0]))), 1.0}
+	// Create the model view matrix using the u v n properties, looking at the origin
+	modelView = mgl32.LookAt(eye.X(), eye.Y(), eye.Z(), at.X(), at.Y(), at.Z(), up.X(), up.Y(), up.Z())
+	// An orthographic projection
+	projection = ortho(left, right, bottom, top, near, far)
+
+	return modelView, projection
+}
+
 // Performs the scalar transformation
 func ortho(left, right, bottom, top, near, far float32) mgl32.Mat4 {
 
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager/golden"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrtho(t *testing.T) {
+	m := ortho(-2, 2, -1, 1, -1, 1)
+
+	assert.Equal(t, mgl32.Vec4{1, 1, 0, 1}, m.Mul4x1(mgl32.Vec4{2, 1, 0, 1}))
+	assert.Equal(t, mgl32.Vec4{-1, -1, 0, 1}, m.Mul4x1(mgl32.Vec4{-2, -1, 0, 1}))
+}
+
+func TestViewGolden(t *testing.T) {
+	Positions, Colors = nil, nil
+	oldTheta, oldPhi := theta[0], phi
+	t.Cleanup(func() {
+		Positions, Colors = nil, nil
+		theta[0], phi = oldTheta, oldPhi
+	})
+
+	glm := &graphicsManager.GLManager{}
+	glm.SetGeoVertices(v3DCube)
+	glm.SetColorVertices(cubeColors)
+	colorCube(glm)
+
+	// Look at the cube off axis so three faces show
+	theta[0], phi = 0.6, 0.8
+	modelView, projection := viewMatrices()
+
+	golden.AssertScene(t, "ortho_lookat", 128, 128, func(r *graphicsManager.Rasterizer) {
+		r.ClearColor = mgl32.Vec4{1, 1, 1, 1}
+		r.DepthTest = true
+		// gl_Position = uProjectionMatrix * uModelViewMatrix * aPosition, then z is flipped
+		r.Transform = mgl32.Scale3D(1, 1, -1).Mul4(projection).Mul4(modelView)
+		r.Clear()
+		r.DrawFloat32Storage(graphicsManager.Float32Storage{ObjVecFloats: Positions, VertexColorFloats: Colors})
+	}, golden.Options{Tolerance: golden.DefaultTolerance})
+}
+
+// The shaders are checked against each other here since CI has no GL
+func TestShaderInterfaces(t *testing.T) {
+	glm := graphicsManager.GLManager{
+		VS:           VERTEXSHADERSOURCE,
+		FS:           FRAGMENTSHADERSOURCE,
+		Preprocessor: graphicsManager.NewPreprocessor(graphicsManager.ShaderLibrary),
+	}
+	diagnostics, err := glm.CheckInterfaces()
+	assert.NoError(t, err)
+	for _, d := range diagnostics {
+		t.Error(d.Format())
+	}
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
 	"github.com/go-gl/gl/v4.1-core/gl"
-	"github.com/go-gl/glfw/v3.3/glfw"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -35,49 +34,27 @@ var (
 func main() {
 	runtime.LockOSThread()
 
-	//TODO: Create a GL struct that handles the gl library through composition
-
-	if err := glfw.Init(); err != nil {
-		fmt.Println("glfw.Init() failed:", err)
-		return
-	}
-	defer glfw.Terminate()
-
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	window, err := glfw.CreateWindow(800, 600, "Test Window Instance", nil, nil)
+	glm, err := graphicsManager.NewGLManager(graphicsManager.WithTitle("Test Window Instance"))
 	if err != nil {
-		fmt.Println("glfw.CreateWindow() failed:", err)
+		fmt.Println("NewGLManager() failed:", err)
 		return
 	}
+	defer glm.Terminate()
 
-	window.MakeContextCurrent()
-
-	if err := gl.Init(); err != nil {
-		fmt.Println("gl.Init() failed:", err)
-
-	}
-
-	glm := graphicsManager.GLManager{
-		Window: window,
-		VS: `
+	glm.VS = `
 		#version 410
 		in vec3 vp;
 		void main() {
 			gl_Position = vec4(vp, 1.0);
 		}
-			` + "\x00",
-		FS: `
+			`
+	glm.FS = `
 		#version 410
 		out vec4 frag_colour;
 		void main() {
 			frag_colour = vec4(1.0, 0.0, 0.0, 1.0);
 		}
-			` + "\x00",
-	}
+			`
 
 	// Set shader sources
 
@@ -86,8 +63,14 @@ func main() {
 
 	// Once the shader sources are configured we can create a program
 
-	glm.SetProgram()
-	glm.BindProgram()
+	if err := glm.SetProgram(); err != nil {
+		fmt.Println("SetProgram() failed:", graphicsManager.FormatShaderError(err))
+		return
+	}
+	if err := glm.BindProgram(); err != nil {
+		fmt.Println("BindProgram() failed:", err)
+		return
+	}
 
 	glm.SetGeoVertices(cubeVertices)
 	fmt.Println("Instance vec3 slice:", glm.Vertices())
@@ -97,7 +80,7 @@ func main() {
 	// Create the buffer object that holds the positions, normals, colors and texture coordinates.
 	// The vbo can store this data on the GPU
 	// Multiple VBO's can be set up
-	// TODO: Create a buffer pool and pointers to the last, next, and current buffers for use
+	// Geometry that changes every frame goes through glm.BufferPool() instead
 
 	glm.BindVBOs()
 	fmt.Println("Instance VBO: ", glm.VBOs())
@@ -105,10 +88,25 @@ func main() {
 	glm.BindVAOs()
 	fmt.Println("Instance VAO: ", glm.VAOs())
 
+	// Point vp at the geometry buffer, the w component is dropped
+	gl.BindVertexArray(glm.VAOs()[0])
+	gl.BindBuffer(gl.ARRAY_BUFFER, glm.VBOs()[0])
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 3, gl.FLOAT, false, 4*4, nil)
+	gl.BindVertexArray(0)
+
+	// The 8 corners are uploaded once and shared through the index buffer
+	glm.SetIndices(cubeIndices)
+	if err := glm.BindEBO(); err != nil {
+		fmt.Println("BindEBO() failed:", err)
+		return
+	}
+
 	glm.RenderCall = func() {
 
 		// Drawing for cube
-		gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		glm.DrawElements(gl.TRIANGLES)
 
 		fmt.Println("Render call")
 		//fmt.Println("VAO", glm.VAO())
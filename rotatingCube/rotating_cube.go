@@ -1,8 +1,12 @@
 package main
 
 import (
+	_ "embed"
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
+	"time"
 
 	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
 
@@ -24,60 +28,17 @@ import (
 // Pass as much math as you can to the GPU
 // Quaternions prevent gimbal lock
 
-const (
-	VERTEXSHADERSOURCE = `
-	#version 410
-
-	in vec4 aPosition;
-	in vec4 aColor;
-	out vec4 vColor;
-
-	uniform vec3 uTheta;
-
-	// quaternion multiplier
-
-	vec4 multq(vec4 a, vec4 b)
-	{
-		return (vec4(a.x*b.x - dot(a.yzw, b.yzw), a.x*b.yzw+b.x*a.yzw+cross(b.yzw, a.yzw)));
-	}
-
-	// inverse quaternion
-
-	vec4 invq(vec4 a)
-	{
-		return (vec4(a.x, -a.yzw)/dot(a,a));
-	}
-
-
+// The shaders live in shaders/ so -shaders can hot reload them, the
+// embedded copies are what a plain go run uses
+var (
+	//go:embed shaders/cube.vert
+	VERTEXSHADERSOURCE string
 
-	void main() {
-		vec3 angles = radians( uTheta );
-		vec4 r;
-		vec4 p;
-		vec4 rx, ry, rz;
-		vec3 c = cos(angles/2.0);
-		vec3 s = sin(angles/2.0);
-		rx = vec4(c.x, -s.x, 0.0, 0.0); // x rot quat
-		ry = vec4(c.y, 0.0, s.y, 0.0); // y rot quat
-		rz = vec4(c.z, 0.0, 0.0, s.z); // z rot quat
-		r = multq(rx, multq(ry, rz)); // rot quat
-		p = vec4(0.0, aPosition.xyz); // input point quat
-		p = multq(r, multq(p, invq(r))); // rotated point quat
-		gl_Position = vec4( p.yzw, 1.0); // Convert to homogenous coords
-		gl_Position.z = -gl_Position.z; // inverse/reflect
-		vColor = aColor;
+	//go:embed shaders/cube.frag
+	FRAGMENTSHADERSOURCE string
 
-	}
-		` + "\x00"
-
-	FRAGMENTSHADERSOURCE = `
-	#version 410
-	in vec4 vColor;
-	out vec4 fColor;
-	void main() {
-		fColor = vColor;
-	}
-		` + "\x00"
+	shaderDir = flag.String("shaders", "", "load cube.vert/cube.frag from this directory and reload them on change, includes placed there override the library, e.g. rotatingCube/shaders")
+	glDebug   = flag.Bool("gldebug", false, "report GL errors and driver warnings as they happen")
 )
 
 const (
@@ -129,62 +90,55 @@ var (
 
 func main() {
 	runtime.LockOSThread()
+	flag.Parse()
 
-	if err := glfw.Init(); err != nil {
-		fmt.Println("glfw.Init() failed:", err)
-		return
+	opts := []graphicsManager.Option{graphicsManager.WithTitle("Rotating Cube")}
+	if *glDebug {
+		opts = append(opts, graphicsManager.WithDebug(graphicsManager.DebugSeverityLow))
 	}
-	defer glfw.Terminate()
-
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	window, err := glfw.CreateWindow(800, 600, "Rotating Cube", nil, nil)
+	glm, err := graphicsManager.NewGLManager(opts...)
 	if err != nil {
-		fmt.Println("glfw.CreateWindow() failed:", err)
+		fmt.Println("NewGLManager() failed:", err)
 		return
 	}
-
-	window.MakeContextCurrent()
-
-	if err := gl.Init(); err != nil {
-		fmt.Println("gl.Init() failed:", err)
-
-	}
-
-	glm := graphicsManager.GLManager{
-		Window: window,
-		VS:     VERTEXSHADERSOURCE,
-		FS:     FRAGMENTSHADERSOURCE,
+	defer glm.Terminate()
+
+	glm.VS = VERTEXSHADERSOURCE
+	glm.FS = FRAGMENTSHADERSOURCE
+	glm.Preprocessor = graphicsManager.NewPreprocessor(graphicsManager.ShaderLibrary)
+	if *shaderDir != "" {
+		// RunLoop picks up edits from here on
+		if err := glm.LoadShaders(os.DirFS(*shaderDir), "cube.vert", "cube.frag"); err != nil {
+			fmt.Println("LoadShaders() failed:", err)
+			return
+		}
 	}
 
 	glm.NewVec4Storage()
 	glm.NewFloat32Storage()
 
-	glm.Window.SetMouseButtonCallback(mouseEventListener)
+	glm.GetWindow().SetMouseButtonCallback(mouseEventListener)
 
 	// Set clear color
 	gl.ClearColor(1.0, 1.0, 1.0, 1.0)
 	gl.Enable(gl.DEPTH_TEST)
 	gl.Viewport(0, 0, 800, 600)
-	if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-		fmt.Println("OpenGL error after drawing colors:", errCode)
-		return
-	}
 
 	// Once the shader sources are configured we can create a program
 
-	glm.SetProgram()
+	if err := glm.SetProgram(); err != nil {
+		fmt.Println("SetProgram() failed:", graphicsManager.FormatShaderError(err))
+		return
+	}
 
-	// Maybe here we send attribute data
-	shaderLocName := gl.Str("uTheta" + "\x00")
-	// Go strings need to be converted into null-terminated C strings.
-	thetaLoc := gl.GetUniformLocation(glm.GetProgram(), shaderLocName)
+	// Uniform locations are looked up once and cached by the shader
+	shader := glm.Shader()
 
 	// You can send floats, scalars, vectors, matrices to uniform
-	glm.BindProgram()
+	if err := glm.BindProgram(); err != nil {
+		fmt.Println("BindProgram() failed:", err)
+		return
+	}
 
 	glm.SetGeoVertices(v3DCube)
 	glm.SetColorVertices(cubeColors)
@@ -192,73 +146,49 @@ func main() {
 	// Create the buffer object that holds the positions, normals, colors and texture coordinates.
 	// The SINGLE vbo can store this data on the GPU for each unique object
 	// Multiple VBO's can be set up
-	// TODO: Create a buffer pool and pointers to the last, next, and current buffers for use
+	// Geometry that changes every frame goes through glm.BufferPool() instead
 	colorCube(glm)
 
-	// Find shader variable name
-	geoCname := gl.Str("aPosition" + "\x00")
-	positionLoc := gl.GetAttribLocation(glm.GetProgram(), geoCname)
-
-	colorCname := gl.Str("aColor" + "\x00")
-	colorLoc := gl.GetAttribLocation(glm.GetProgram(), colorCname)
-
-	// Bind VAO.
-	// Bind VBO.
-	// Set vertex attribute pointers.
-	// Unbind VBO
-	// Unbind VAO.
-	geoVBO := makeVbo()
-	cVBO := makeVbo()
-	VAO := makeVao()
-
-	// Create and bind a single VAO
-	gl.BindVertexArray(VAO)
-
-	// Setup for position data
-	gl.BindBuffer(gl.ARRAY_BUFFER, geoVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(Positions)*4*4), gl.Ptr(&Positions[0]), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(uint32(positionLoc))
-	gl.VertexAttribPointer(uint32(positionLoc), 4, gl.FLOAT, false, 0, nil)
-
-	// Setup for color data
-	gl.BindBuffer(gl.ARRAY_BUFFER, cVBO)
-	gl.BufferData(gl.ARRAY_BUFFER, int(len(Colors)*4*4), gl.Ptr(&Colors[0]), gl.STATIC_DRAW)
-	gl.EnableVertexAttribArray(uint32(colorLoc))
-	gl.VertexAttribPointer(uint32(colorLoc), 4, gl.FLOAT, false, 0, nil)
-
-	// Unbind VBO and VAO
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	gl.BindVertexArray(0)
+	// Swap the 8 corners for the expanded face vertices, the layout finds
+	// aPosition and aColor in the program and sets up the VAO for us
+	glm.SetGeoVertices(graphicsManager.Float32ToVec4(Positions))
+	glm.SetColorVertices(graphicsManager.Float32ToVec4(Colors))
+	glm.SetVertexLayout(graphicsManager.NewVertexLayout(true,
+		graphicsManager.Float4(graphicsManager.PositionAttribute),
+		graphicsManager.Float4(graphicsManager.ColorAttribute),
+	))
+	if err := glm.BindLayout(); err != nil {
+		fmt.Println("BindLayout() failed:", err)
+		return
+	}
+	if report := glm.LayoutReport(); !report.Clean() {
+		fmt.Println("Warning:", report)
+	}
 
-	glm.RenderCall = func() {
-		if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-			fmt.Println("OpenGL error before rendering:", errCode)
-			return
-		}
+	// Mouse drags turn the cube at the fixed update rate, rendering only
+	// draws whatever theta is
+	glm.UpdateCall = func(time.Duration) {
+		updateRotation(glm.GetWindow())
+	}
 
+	glm.RenderCall = func() {
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// Rotating cube render
-		updateRotation(glm.Window)
-
 		// Update the uniform
-		gl.Uniform3fv(thetaLoc, 1, &theta[0])
-
-		// Bind the single VAO
-		gl.BindVertexArray(VAO)
-		gl.DrawArrays(gl.TRIANGLES, 0, numPositions)
-
-		if errCode := gl.GetError(); errCode != gl.NO_ERROR {
-			fmt.Println("OpenGL error after drawing:", errCode)
+		if err := shader.SetVec3("uTheta", mgl32.Vec3{theta[0], theta[1], theta[2]}); err != nil {
+			fmt.Println("SetVec3() failed:", err)
 			return
 		}
+
+		// Bind the single VAO
+		glm.DrawArrays(gl.TRIANGLES)
 	}
 
 	glm.RunLoop(60)
 
 }
 
-func colorCube(glm graphicsManager.GLManager) {
+func colorCube(glm *graphicsManager.GLManager) {
 	Quad(1, 0, 3, 2, glm)
 	Quad(2, 3, 7, 6, glm)
 	Quad(3, 0, 4, 7, glm)
@@ -268,23 +198,7 @@ func colorCube(glm graphicsManager.GLManager) {
 
 }
 
-func makeVbo() uint32 {
-
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-
-	return vbo
-}
-
-func makeVao() uint32 {
-
-	var vao uint32
-	gl.GenVertexArrays(1, &vao)
-
-	return vao
-}
-
-func Quad(a, b, c, d int, glm graphicsManager.GLManager) {
+func Quad(a, b, c, d int, glm *graphicsManager.GLManager) {
 
 	vertices := glm.Vec4Storage().ObjectVertices
 
@@ -293,21 +207,14 @@ func Quad(a, b, c, d int, glm graphicsManager.GLManager) {
 	var indices = []int{a, b, c, a, c, d}
 
 	for i := 0; i < len(indices); i++ {
-		fmt.Println(i)
-		Positions = append(Positions, vecToFloat32(vertices[indices[i]])...)
+		// A Vec4 is already 4 floats, slicing it appends without a temporary
+		Positions = append(Positions, vertices[indices[i]][:]...)
 
-		Colors = append(Colors, vecToFloat32(vertexColors[a])...)
+		Colors = append(Colors, vertexColors[a][:]...)
 	}
 
 }
 
-func vecToFloat32(vec mgl32.Vec4) []float32 {
-	float32Array := make([]float32, 0, 4)
-
-	float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z(), vec.W())
-	return float32Array
-}
-
 func mouseEventListener(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
 	if button == glfw.MouseButtonLeft {
 		if action == glfw.Press {
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager/golden"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+// cubeTransform is the CPU version of VERTEXSHADERSOURCE. multq, in the
+// shared quaternion.glsl, is written with cross(b, a), which makes
+// multq(a, b) the Hamilton product b*a, so the shader ends up rotating by
+// the inverse of rz*ry*rx before flipping z.
+func cubeTransform(theta mgl32.Vec3) mgl32.Mat4 {
+	return mgl32.Scale3D(1, 1, -1).
+		Mul4(mgl32.HomogRotate3DX(mgl32.DegToRad(theta.X()))).
+		Mul4(mgl32.HomogRotate3DY(mgl32.DegToRad(-theta.Y()))).
+		Mul4(mgl32.HomogRotate3DZ(mgl32.DegToRad(-theta.Z())))
+}
+
+func buildCube(t *testing.T) {
+	t.Helper()
+	Positions, Colors = nil, nil
+	t.Cleanup(func() { Positions, Colors = nil, nil })
+
+	glm := &graphicsManager.GLManager{}
+	glm.SetGeoVertices(v3DCube)
+	glm.SetColorVertices(cubeColors)
+	colorCube(glm)
+}
+
+func TestColorCube(t *testing.T) {
+	buildCube(t)
+
+	// 6 faces of 2 triangles, 4 floats per vertex
+	assert.Len(t, Positions, int(numPositions)*4)
+	assert.Len(t, Colors, int(numPositions)*4)
+}
+
+func TestColorCubeGolden(t *testing.T) {
+	buildCube(t)
+
+	golden.AssertScene(t, "color_cube", 128, 128, func(r *graphicsManager.Rasterizer) {
+		r.ClearColor = mgl32.Vec4{1, 1, 1, 1}
+		r.DepthTest = true
+		r.Transform = cubeTransform(mgl32.Vec3{30, 45, 0})
+		r.Clear()
+		r.DrawFloat32Storage(graphicsManager.Float32Storage{ObjVecFloats: Positions, VertexColorFloats: Colors})
+	}, golden.Options{Tolerance: golden.DefaultTolerance})
+}
+
+// The shaders are checked against each other here since CI has no GL
+func TestShaderInterfaces(t *testing.T) {
+	glm := graphicsManager.GLManager{
+		VS:           VERTEXSHADERSOURCE,
+		FS:           FRAGMENTSHADERSOURCE,
+		Preprocessor: graphicsManager.NewPreprocessor(graphicsManager.ShaderLibrary),
+	}
+	diagnostics, err := glm.CheckInterfaces()
+	assert.NoError(t, err)
+	for _, d := range diagnostics {
+		t.Error(d.Format())
+	}
+}
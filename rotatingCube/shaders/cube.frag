@@ -0,0 +1,6 @@
+#version 410
+in vec4 vColor;
+out vec4 fColor;
+void main() {
+	fColor = vColor;
+}
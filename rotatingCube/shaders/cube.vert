@@ -0,0 +1,29 @@
+#version 410
+
+in vec4 aPosition;
+in vec4 aColor;
+out vec4 vColor;
+
+uniform vec3 uTheta;
+
+// multq and invq come from the graphicsManager shader library
+#include "quaternion.glsl"
+
+void main() {
+	vec3 angles = radians( uTheta );
+	vec4 r;
+	vec4 p;
+	vec4 rx, ry, rz;
+	vec3 c = cos(angles/2.0);
+	vec3 s = sin(angles/2.0);
+	rx = vec4(c.x, -s.x, 0.0, 0.0); // x rot quat
+	ry = vec4(c.y, 0.0, s.y, 0.0); // y rot quat
+	rz = vec4(c.z, 0.0, 0.0, s.z); // z rot quat
+	r = multq(rx, multq(ry, rz)); // rot quat
+	p = vec4(0.0, aPosition.xyz); // input point quat
+	p = multq(r, multq(p, invq(r))); // rotated point quat
+	gl_Position = vec4( p.yzw, 1.0); // Convert to homogenous coords
+	gl_Position.z = -gl_Position.z; // inverse/reflect
+	vColor = aColor;
+
+}
@@ -2,10 +2,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"runtime"
 	"time"
 
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 
@@ -18,6 +20,8 @@ const (
 	FPS    = 60
 )
 
+var glDebug = flag.Bool("gldebug", false, "report GL errors and driver warnings as they happen")
+
 var (
 	vertices = []mgl32.Vec3{
 		{0.0, 1.0, 0.0},
@@ -26,9 +30,10 @@ var (
 	}
 
 	VAO uint32
-	VBO uint32
+	// ring streams the gasket vertices, a new region every frame
+	ring *graphicsManager.BufferRing
 
-	float32vertices = vec3ToFloat32(vertices)
+	float32vertices = graphicsManager.Vec3sAsFloat32(vertices)
 
 	vertexShaderSource = `
 		#version 410
@@ -36,7 +41,7 @@ var (
 		void main() {
 			gl_Position = vec4(vp, 1.0);
 		}
-	` + "\x00"
+	`
 
 	fragmentShaderSource = `
 		#version 410
@@ -44,7 +49,7 @@ var (
 		void main() {
 			frag_colour = vec4(1.0, 0.0, 0.0, 1.0);
 		}
-	` + "\x00"
+	`
 )
 
 //Conceptually I thought I would be generating the points one frame at a time but now realize that the
@@ -52,48 +57,34 @@ var (
 
 func main() {
 	runtime.LockOSThread()
+	flag.Parse()
 
 	fmt.Println("Float32Vert value at init: ", float32vertices)
 
 	//TODO: Turn into a struct that holds information related to the window, openGL program, and vertex and buffer information.
 
-	// Window initialization using the gl-go/glfw package which acts as the glue for the OS
+	// Window and context creation, including loading the GL functions, is handled by the graphicsManager package
 
-	if err := glfw.Init(); err != nil {
-		fmt.Println("glfw.Init() failed:", err)
-		return
+	opts := []graphicsManager.Option{graphicsManager.WithSize(width, height), graphicsManager.WithTitle("Sierpinski's Gasket")}
+	if *glDebug {
+		opts = append(opts, graphicsManager.WithDebug(graphicsManager.DebugSeverityLow))
 	}
-	defer glfw.Terminate()
-
-	// Information needed for the window
-
-	glfw.WindowHint(glfw.ContextVersionMajor, 4)
-	glfw.WindowHint(glfw.ContextVersionMinor, 1)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-
-	// Creating of the window context
-
-	window, err := glfw.CreateWindow(width, height, "Sierpinski's Gasket", nil, nil)
+	glm, err := graphicsManager.NewGLManager(opts...)
 	if err != nil {
-		fmt.Println("glfw.CreateWindow() failed:", err)
+		fmt.Println("NewGLManager() failed:", err)
 		return
 	}
+	defer glm.Terminate()
 
-	// Binding of the window context
-	window.MakeContextCurrent()
+	window := glm.GetWindow()
 
 	window.SetKeyCallback(keyCallback)
 
-	if err := gl.Init(); err != nil {
-		fmt.Println("gl.Init() failed:", err)
-		return
-	}
-
-	// Vertex buffer object and vertex binder attribute are initialized and bound before rendering.
+	// The vertex array is set up before rendering, the buffers come from a
+	// triple buffered ring so the CPU never writes the one being drawn
 
-	VBO = makeVbo(float32vertices)
-	VAO = makeVao(VBO)
+	ring = glm.BufferPool().NewRing(graphicsManager.DefaultRingRegions, 4*len(float32vertices))
+	VAO = makeVao()
 
 	// Depth testing is important for rendering 2D objects in 3D space, checking for vertexes clipping one another
 
@@ -109,10 +100,6 @@ func main() {
 		return
 	}
 
-	for errCode := gl.GetError(); errCode != gl.NO_ERROR; errCode = gl.GetError() {
-		fmt.Println("OpenGL error: ", errCode)
-	}
-
 	// Bind the program
 	gl.UseProgram(program)
 	// TODO: turn this into a draw function
@@ -130,10 +117,6 @@ func main() {
 		// The main loop handles the actual draw calls and parsing of the buffer data into the
 		// the correct format for reading into buffer
 
-		for errCode := gl.GetError(); errCode != gl.NO_ERROR; errCode = gl.GetError() {
-			fmt.Println("OpenGL error: ", errCode)
-		}
-
 		// The vertices are reset every loop to avoid appending infinitely
 		float32vertices = nil
 		// PollEvents is an event listener looking for keybind interactions/mouse clicks
@@ -149,9 +132,6 @@ func main() {
 
 func newProgram(vertexShaderSource, fragmentShaderSource string) (uint32, error) {
 
-	fmt.Println("Vertex Shader Source:\n", vertexShaderSource)
-	fmt.Println("Fragment Shader Source:\n", fragmentShaderSource)
-
 	// Compile the shaders from the given source, turning it into a uint32 value
 	vertexShader, err := compileShader(vertexShaderSource, gl.VERTEX_SHADER)
 	if err != nil {
@@ -208,28 +188,15 @@ func compileShader(source string, shaderType uint32) (uint32, error) {
 	return shader, nil
 }
 
-func vec3ToFloat32(vec3Array []mgl32.Vec3) []float32 {
-	// This is my version of "flatten.js" as I am working with mgl32.Vec3 structs in Go to do vector math but need them squashed into an array of float32 to feed the buffer
-	float32Array := make([]float32, 0, len(vec3Array)*3)
-
-	for _, vec := range vec3Array {
-		float32Array = append(float32Array, vec.X(), vec.Y(), vec.Z())
-	}
-
-	return float32Array
-}
-
-func makeVao(vbo uint32) uint32 {
-	// Vertex array is generated and bound, attributes are set up so we know how many to read at a time and what data type will be read
+func makeVao() uint32 {
+	// Vertex array is generated and bound, the attribute pointer is set per frame in renderGasket
+	// since the buffer it reads from changes every frame
 
 	var vao uint32
 	gl.GenVertexArrays(1, &vao)
 	// I was generating an empty buffer here, 5 hours to find.
 
 	gl.BindVertexArray(vao)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-
-	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 0, 0)
 	gl.EnableVertexAttribArray(0)
 
 	return vao
@@ -237,12 +204,36 @@ func makeVao(vbo uint32) uint32 {
 
 // renderGasket is the recursive vector math to produce the fractal image, returning the values to the buffer and feeding once the recursion is complete
 func renderGasket(v0, v1, v2 mgl32.Vec3, depth int) {
+	float32vertices = gasketVertices(float32vertices, v0, v1, v2, depth)
+
+	// Write the set of float32vertices into the next ring region, it stays bound for the pointer below
+	if _, err := ring.UploadFloats(float32vertices); err != nil {
+		fmt.Println("Gasket upload failed:", err)
+		return
+	}
+	gl.BindVertexArray(VAO)
+	gl.VertexAttribPointerWithOffset(0, 3, gl.FLOAT, false, 0, 0)
+
+	// The draw call using triangle primitives
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(float32vertices)/3))
+	//gl.DrawArrays(gl.LINES, 0, int32(len(float32vertices)/3))
+	// Using the POINTS primitive will only render the dot location of each vertice instead of connecting them like the triangle primitive
+	// gl.DrawArrays(gl.POINTS, 0, int32(len(float32vertices)/3))
+	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+
+	// The region is off limits until the GPU has drawn from it
+	ring.Fence()
+
+}
+
+// gasketVertices is the recursion on its own, appending the triangles of the fractal to vertices
+// It doesn't touch GL so the geometry can be rendered headlessly in the tests
+func gasketVertices(vertices []float32, v0, v1, v2 mgl32.Vec3, depth int) []float32 {
 	// The recursive call for the fractal rendering
 
 	//
 	if depth == 0 {
-		float32vertices = pushTriangle(float32vertices, v0, v1, v2)
-		return
+		return pushTriangle(vertices, v0, v1, v2)
 	}
 
 	// Calculate midpoints of edges
@@ -253,38 +244,17 @@ func renderGasket(v0, v1, v2 mgl32.Vec3, depth int) {
 	//fmt.Printf("Depth: %d, Vertices: (%v, %v, %v)\n", depth, v0, v1, v2)
 
 	// Recursive calls for three sub-triangles
-	renderGasket(v0, mid01, mid20, depth-1)
-	renderGasket(mid01, v1, mid12, depth-1)
-	renderGasket(mid20, mid12, v2, depth-1)
-
-	// Update buffer bindings for the set of float32vertices for rendering
-	gl.BindBuffer(gl.ARRAY_BUFFER, VBO)
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(float32vertices), gl.Ptr(float32vertices), gl.STATIC_DRAW)
-
-	// The draw call using triangle primitives
-	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(float32vertices)/3))
-	//gl.DrawArrays(gl.LINES, 0, int32(len(float32vertices)/3))
-	// Using the POINTS primitive will only render the dot location of each vertice instead of connecting them like the triangle primitive
-	// gl.DrawArrays(gl.POINTS, 0, int32(len(float32vertices)/3))
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
+	vertices = gasketVertices(vertices, v0, mid01, mid20, depth-1)
+	vertices = gasketVertices(vertices, mid01, v1, mid12, depth-1)
+	vertices = gasketVertices(vertices, mid20, mid12, v2, depth-1)
 
+	return vertices
 }
 
 func pushTriangle(vertices []float32, v0, v1, v2 mgl32.Vec3) []float32 {
 	// Take the indiviual float32 values and append them
 	return append(vertices, v0.X(), v0.Y(), v0.Z(), v1.X(), v1.Y(), v1.Z(), v2.X(), v2.Y(), v2.Z())
 }
-func makeVbo(vertices []float32) uint32 {
-	// The first binding of the buffer when called at initialization
-	var vbo uint32
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	// 32 bits 4 bytes
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
-	gl.BindBuffer(gl.ARRAY_BUFFER, 0)
-	return vbo
-}
-
 func keyCallback(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
 	// Check if the key is pressed and not released
 	//Keyboard interaction to render different depths
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager"
+	"github.com/LITFAMWOKE93/alleviated-wave/graphicsManager/golden"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasketVertices(t *testing.T) {
+	// Every level of recursion triples the triangle count
+	for depth, triangles := range []int{1, 3, 9, 27} {
+		result := gasketVertices(nil, vertices[0], vertices[1], vertices[2], depth)
+		assert.Len(t, result, triangles*9)
+	}
+}
+
+func TestGasketGolden(t *testing.T) {
+	for _, depth := range []int{1, 6} {
+		t.Run(fmt.Sprint(depth), func(t *testing.T) {
+			golden.AssertScene(t, fmt.Sprintf("gasket_depth%d", depth), 128, 128, func(r *graphicsManager.Rasterizer) {
+				// Same clear color, depth test and solid red fragment shader as main
+				r.ClearColor = mgl32.Vec4{1, 1, 1, 1}
+				r.FlatColor = mgl32.Vec4{1, 0, 0, 1}
+				r.DepthTest = true
+				r.Clear()
+
+				flat := gasketVertices(nil, vertices[0], vertices[1], vertices[2], depth)
+				positions := make([]mgl32.Vec4, 0, len(flat)/3)
+				for i := 0; i+2 < len(flat); i += 3 {
+					positions = append(positions, mgl32.Vec4{flat[i], flat[i+1], flat[i+2], 1})
+				}
+				r.DrawTriangles(positions, nil)
+			}, golden.Options{Tolerance: golden.DefaultTolerance})
+		})
+	}
+}
+
+func TestShaderInterfaces(t *testing.T) {
+	diagnostics, err := graphicsManager.CheckShaderInterfaces(nil, map[string]string{
+		"vertex":   vertexShaderSource,
+		"fragment": fragmentShaderSource,
+	})
+	assert.NoError(t, err)
+	for _, d := range diagnostics {
+		t.Error(d.Format())
+	}
+}